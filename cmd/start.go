@@ -5,6 +5,8 @@ Copyright © 2022 Noueman KHALIKINE <noueman.khal@gmail.com>
 package cmd
 
 import (
+	"time"
+
 	"github.com/NouemanKHAL/sugoku/pkg/config"
 	"github.com/NouemanKHAL/sugoku/pkg/server"
 	"github.com/spf13/cobra"
@@ -12,8 +14,9 @@ import (
 )
 
 var (
-	port     int
-	logLevel string
+	port             int
+	logLevel         string
+	maxSolveDuration time.Duration
 )
 
 var startCmd = &cobra.Command{
@@ -22,11 +25,13 @@ var startCmd = &cobra.Command{
 	Long: `
 	Start a sudoku server listening on the given port (-p) or 7007 by default.
 	Logging levels can be controlled using the (-l) flag. 
-	List of supported logging levels (TRACE, DEBUG, INFO, WARN, ERROR).`,
+	List of supported logging levels (TRACE, DEBUG, INFO, WARN, ERROR).
+	Requests solving or generating puzzles are aborted after the given duration (-t), 10s by default, 0 disables the limit.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		configuration := config.Config{
-			Port:     port,
-			LogLevel: logLevel,
+			Port:             port,
+			LogLevel:         logLevel,
+			MaxSolveDuration: maxSolveDuration,
 		}
 		server.StartServer(configuration)
 	},
@@ -39,5 +44,8 @@ func init() {
 
 	startCmd.Flags().StringVarP(&logLevel, "log-level", "l", "INFO", "Logging level")
 	viper.BindPFlag("log-level", startCmd.Flags().Lookup("log-level"))
+
+	startCmd.Flags().DurationVarP(&maxSolveDuration, "max-solve-duration", "t", 10*time.Second, "Maximum duration spent solving or generating a puzzle")
+	viper.BindPFlag("max-solve-duration", startCmd.Flags().Lookup("max-solve-duration"))
 	// TODO: add support for log file
 }
@@ -1,7 +1,10 @@
 package config
 
+import "time"
+
 type Config struct {
-	Port     int
-	LogFile  string
-	LogLevel string
+	Port             int
+	LogFile          string
+	LogLevel         string
+	MaxSolveDuration time.Duration
 }
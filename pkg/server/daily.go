@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NouemanKHAL/sugoku/pkg/sudoku"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// dailyDateLayout is the format of the date query parameter of the puzzle of the day
+	dailyDateLayout = "2006-01-02"
+	// dailyLevel is the level of the puzzle of the day unless told otherwise
+	dailyLevel = "medium"
+	// dailyCacheSize bounds the number of puzzles of the day kept in memory
+	dailyCacheSize = 64
+	// dailyPastMaxAge is how long the clients may keep the puzzle of a past day, which never changes
+	dailyPastMaxAge = 365 * 24 * time.Hour
+)
+
+type dailyPuzzle struct {
+	date string
+	grid *sudoku.SudokuGrid
+}
+
+// dailyCache keeps the puzzles of the day by date, level and dimensions, as generating a graded puzzle takes a while.
+// Concurrent requests for a puzzle not cached yet wait for the first one to generate it.
+type dailyCache struct {
+	mu      sync.Mutex
+	puzzles map[string]dailyPuzzle
+	pending map[string]*dailyCall // puzzles being generated by key
+}
+
+// dailyCall is the generation of a puzzle of the day, done is closed once grid or err is set
+type dailyCall struct {
+	done chan struct{}
+	grid *sudoku.SudokuGrid
+	err  error
+}
+
+var dailyPuzzles = newDailyCache()
+
+func newDailyCache() *dailyCache {
+	return &dailyCache{puzzles: map[string]dailyPuzzle{}, pending: map[string]*dailyCall{}}
+}
+
+// get returns the puzzle of the key, calling generate unless it is cached or being generated by another request
+// already, in which case it waits for it. A request cancelled while generating the puzzle hands the generation
+// over to one of those waiting.
+func (c *dailyCache) get(ctx context.Context, key, date string, generate func() (*sudoku.SudokuGrid, error)) (*sudoku.SudokuGrid, error) {
+	for {
+		c.mu.Lock()
+		if p, ok := c.puzzles[key]; ok {
+			c.mu.Unlock()
+			return p.grid, nil
+		}
+		call, ok := c.pending[key]
+		if !ok {
+			call = &dailyCall{done: make(chan struct{})}
+			c.pending[key] = call
+			c.mu.Unlock()
+
+			call.grid, call.err = generate()
+			c.mu.Lock()
+			delete(c.pending, key)
+			if call.err == nil {
+				c.add(key, date, call.grid)
+			}
+			c.mu.Unlock()
+			close(call.done)
+			return call.grid, call.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-call.done:
+		}
+		if !errors.Is(call.err, context.Canceled) {
+			return call.grid, call.err
+		}
+	}
+}
+
+// add caches the puzzle of the given date, dropping a puzzle of the oldest date once the cache is full. The puzzle
+// is left out if none is older, so the puzzles of today are never dropped. c.mu must be held.
+func (c *dailyCache) add(key, date string, sG *sudoku.SudokuGrid) {
+	if len(c.puzzles) >= dailyCacheSize {
+		oldest := ""
+		for k, p := range c.puzzles {
+			if oldest == "" || p.date < c.puzzles[oldest].date {
+				oldest = k
+			}
+		}
+		if c.puzzles[oldest].date >= date {
+			return
+		}
+		delete(c.puzzles, oldest)
+	}
+	c.puzzles[key] = dailyPuzzle{date: date, grid: sG}
+}
+
+// dailySeed derives the seed of the puzzle of the day from its key, so that every level of the day gets its own
+// solution instead of sharing the givens of the easier ones
+func dailySeed(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// etagMatches returns true if the If-None-Match header lists the given entity tag, weakly compared as RFC 7232 asks
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func sudokuDailyHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	pretty := params.Get("pretty")
+	level := params.Get("level")
+	if level == "" {
+		level = dailyLevel
+	}
+
+	// the days of the puzzle of the day start at midnight UTC
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	date := today
+	var result error
+	if params.Get("date") != "" {
+		d, err := time.Parse(dailyDateLayout, params.Get("date"))
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid date: %w", err))
+		}
+		date = d
+	}
+	// the grid is 9x9 with 3x3 subgrids unless told otherwise
+	size, partitionWidth, partitionHeight := 9, 3, 3
+	for name, value := range map[string]*int{"size": &size, "partitionWidth": &partitionWidth, "partitionHeight": &partitionHeight} {
+		if params.Get(name) == "" {
+			continue
+		}
+		n, err := strconv.Atoi(params.Get(name))
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+		*value = n
+	}
+	// the dimensions are part of the key of the cache, they are checked before anything is derived from them:
+	// the size must have symbols, which bounds the empty grid whose validation checks the partitions
+	if _, err := sudoku.SymbolSet("", size); err != nil {
+		result = multierror.Append(result, err)
+	} else if _, err := sudoku.New(size, partitionWidth, partitionHeight); err != nil {
+		result = multierror.Append(result, err)
+	}
+	if result != nil {
+		log.Errorf("error validating request params: %v", result)
+		writeError(w, result, http.StatusBadRequest)
+		return
+	}
+	if date.After(today) {
+		err := fmt.Errorf("the puzzle of %s is not out yet", date.Format(dailyDateLayout))
+		log.Errorf("error validating request params: %v", err)
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+
+	day := date.Format(dailyDateLayout)
+	key := fmt.Sprintf("%s/%s/%dx%dx%d", day, level, size, partitionWidth, partitionHeight)
+	seed := dailySeed(key)
+	sG, err := dailyPuzzles.get(r.Context(), key, day, func() (*sudoku.SudokuGrid, error) {
+		sG, err := sudoku.GenerateSudokuGridContext(r.Context(), size, partitionWidth, partitionHeight, sudoku.WithSeed(seed))
+		if err != nil {
+			log.Errorf("error generating sudoku grid: %v", err)
+			return nil, err
+		}
+		// the puzzle of the day always has a unique solution graded at its level
+		err = sG.SetGridToLevelContext(r.Context(), level, sudoku.WithUniqueSolution(), sudoku.WithGradedDifficulty(sudoku.DefaultGradingAttempts))
+		if err != nil {
+			log.Errorf("error setting the grid to the difficulty level: %v", err)
+			return nil, err
+		}
+		return sG, nil
+	})
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var res []byte
+	if pretty == "true" {
+		res = []byte(sG.ToStringPrettify())
+	} else {
+		res, err = json.Marshal(sG)
+		if err != nil {
+			log.Errorf("error marshalling the response: %v", err)
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// the puzzle of today may be kept until midnight UTC, those of the past days never change
+	maxAge := dailyPastMaxAge
+	if date.Equal(today) {
+		maxAge = today.Add(24 * time.Hour).Sub(time.Now())
+	}
+	h := fnv.New64a()
+	h.Write(res)
+	etag := fmt.Sprintf(`"%x"`, h.Sum64())
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	w.Header().Set(seedHeader, strconv.FormatInt(seed, 10))
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(res)
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NouemanKHAL/sugoku/pkg/sudoku"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// getDaily calls the puzzle of the day handler with the given query and If-None-Match header
+func getDaily(query, etag string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/sudoku/daily?"+query, nil)
+	if etag != "" {
+		r.Header.Set("If-None-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	sudokuDailyHandler(w, r)
+	return w
+}
+
+// maxAge returns the max-age of the Cache-Control header of the response
+func maxAge(w *httptest.ResponseRecorder) int {
+	cacheControl := w.Header().Get("Cache-Control")
+	Expect(cacheControl).To(HavePrefix("public, max-age="))
+	seconds, err := strconv.Atoi(strings.TrimPrefix(cacheControl, "public, max-age="))
+	Expect(err).To(BeNil())
+	return seconds
+}
+
+var _ = Describe("Puzzle of the day", func() {
+	It("serves the same puzzle of a past day, cached for a year", func() {
+		w := getDaily("date=2024-01-01&level=easy", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(maxAge(w)).To(Equal(int(dailyPastMaxAge.Seconds())))
+		etag := w.Header().Get("ETag")
+		Expect(etag).NotTo(BeEmpty())
+		Expect(w.Header().Get(seedHeader)).NotTo(BeEmpty())
+
+		again := getDaily("date=2024-01-01&level=easy", "")
+		Expect(again.Code).To(Equal(http.StatusOK))
+		Expect(again.Body.String()).To(Equal(w.Body.String()))
+		Expect(again.Header().Get("ETag")).To(Equal(etag))
+
+		// another level of the same day is another puzzle
+		other := getDaily("date=2024-01-01&level=medium", "")
+		Expect(other.Code).To(Equal(http.StatusOK))
+		Expect(other.Header().Get("ETag")).NotTo(Equal(etag))
+	})
+
+	It("keeps the puzzle of today until midnight UTC", func() {
+		w := getDaily("level=easy", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		midnight := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		Expect(maxAge(w)).To(BeNumerically("<=", int(time.Until(midnight).Seconds())+1))
+		Expect(maxAge(w)).To(BeNumerically(">=", 0))
+	})
+
+	It("answers not modified when the client has the puzzle already", func() {
+		w := getDaily("date=2024-01-02&level=easy", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		etag := w.Header().Get("ETag")
+
+		for _, header := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+			notModified := getDaily("date=2024-01-02&level=easy", header)
+			Expect(notModified.Code).To(Equal(http.StatusNotModified))
+			Expect(notModified.Body.Len()).To(Equal(0))
+			Expect(notModified.Header().Get("ETag")).To(Equal(etag))
+			Expect(maxAge(notModified)).To(Equal(int(dailyPastMaxAge.Seconds())))
+		}
+
+		modified := getDaily("date=2024-01-02&level=easy", `"other"`)
+		Expect(modified.Code).To(Equal(http.StatusOK))
+		Expect(modified.Body.String()).To(Equal(w.Body.String()))
+	})
+
+	It("does not serve the puzzles of the days to come", func() {
+		tomorrow := time.Now().UTC().Add(24 * time.Hour).Format(dailyDateLayout)
+		Expect(getDaily("date="+tomorrow, "").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("rejects invalid dates and dimensions", func() {
+		for _, query := range []string{
+			"date=yesterday",
+			"size=0&partitionWidth=0&partitionHeight=0",
+			"size=-4&partitionWidth=-2&partitionHeight=2",
+			"size=9&partitionWidth=-3&partitionHeight=-3",
+			"size=9&partitionWidth=2&partitionHeight=3",
+			"size=100&partitionWidth=10&partitionHeight=10",
+			"size=9&partitionWidth=9&partitionHeight=0",
+			"size=9&partitionWidth=0",
+			"size=1000000&partitionWidth=1000&partitionHeight=1000",
+			"size=nine",
+		} {
+			Expect(getDaily(query, "").Code).To(Equal(http.StatusBadRequest), query)
+		}
+	})
+
+	It("drops the puzzles of the oldest dates once full, never those of today", func() {
+		today := time.Now().UTC().Format(dailyDateLayout)
+		c := newDailyCache()
+		put := func(key, date string) {
+			_, err := c.get(context.Background(), key, date, func() (*sudoku.SudokuGrid, error) {
+				return sudoku.New(4, 2, 2)
+			})
+			Expect(err).To(BeNil())
+		}
+		put("today", today)
+		for i := 1; i < dailyCacheSize; i++ {
+			put(fmt.Sprint("old", i), "2024-01-01")
+		}
+		put("past", "2024-01-02")
+		Expect(c.puzzles).To(HaveLen(dailyCacheSize))
+		Expect(c.puzzles).To(HaveKey("today"))
+		Expect(c.puzzles).To(HaveKey("past"))
+
+		for i := 1; i < dailyCacheSize; i++ {
+			put(fmt.Sprint("today", i), today)
+		}
+		put("past again", "2024-01-03")
+		Expect(c.puzzles).To(HaveLen(dailyCacheSize))
+		Expect(c.puzzles).NotTo(HaveKey("past again"))
+		for key, p := range c.puzzles {
+			Expect(p.date).To(Equal(today), key)
+		}
+	})
+
+	It("generates a puzzle once for concurrent requests", func() {
+		c := newDailyCache()
+		var mu sync.Mutex
+		calls := 0
+		release := make(chan struct{})
+		generate := func() (*sudoku.SudokuGrid, error) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			<-release
+			return sudoku.New(4, 2, 2)
+		}
+
+		var wg sync.WaitGroup
+		grids := make([]*sudoku.SudokuGrid, 8)
+		for i := range grids {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				var err error
+				grids[i], err = c.get(context.Background(), "key", "2024-01-01", generate)
+				Expect(err).To(BeNil())
+			}(i)
+		}
+		Eventually(func() int {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return len(c.pending)
+		}).Should(Equal(1))
+		close(release)
+		wg.Wait()
+
+		Expect(calls).To(Equal(1))
+		for _, sG := range grids {
+			Expect(sG).To(BeIdenticalTo(grids[0]))
+		}
+	})
+
+	It("hands the generation over when the request generating the puzzle is cancelled", func() {
+		c := newDailyCache()
+		started, cancelled := make(chan struct{}), make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			_, err := c.get(context.Background(), "key", "2024-01-01", func() (*sudoku.SudokuGrid, error) {
+				close(started)
+				<-cancelled
+				return nil, context.Canceled
+			})
+			Expect(err).To(MatchError(context.Canceled))
+		}()
+		<-started
+
+		done := make(chan *sudoku.SudokuGrid)
+		go func() {
+			defer GinkgoRecover()
+			sG, err := c.get(context.Background(), "key", "2024-01-01", func() (*sudoku.SudokuGrid, error) {
+				return sudoku.New(4, 2, 2)
+			})
+			Expect(err).To(BeNil())
+			done <- sG
+		}()
+		close(cancelled)
+		Eventually(done).Should(Receive(Not(BeNil())))
+	})
+
+	It("stops waiting for a puzzle once the request is cancelled", func() {
+		c := newDailyCache()
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+		go func() {
+			defer GinkgoRecover()
+			c.get(context.Background(), "key", "2024-01-01", func() (*sudoku.SudokuGrid, error) {
+				close(started)
+				<-release
+				return sudoku.New(4, 2, 2)
+			})
+		}()
+		<-started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := c.get(ctx, "key", "2024-01-01", func() (*sudoku.SudokuGrid, error) {
+			Fail("the puzzle is being generated already")
+			return nil, nil
+		})
+		Expect(err).To(MatchError(context.Canceled))
+	})
+})
@@ -1,11 +1,16 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/NouemanKHAL/sugoku/pkg/config"
 	"github.com/NouemanKHAL/sugoku/pkg/middleware"
@@ -37,6 +42,67 @@ func initLogger(cfg config.Config) {
 	}
 }
 
+// errorStatus returns the status code reporting err: 408 if the request was cancelled by the client,
+// 503 if the solving budget of the request is exhausted, 422 if the grid breaks the rules or cannot be completed
+// or no puzzle graded at the requested level is found,
+// 400 if the grid is malformed, or defaultStatus otherwise
+func errorStatus(err error, defaultStatus int) int {
+	var verr *sudoku.ValidationError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return http.StatusRequestTimeout
+	case errors.Is(err, sudoku.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &verr), errors.Is(err, sudoku.ErrConflict), errors.Is(err, sudoku.ErrNoSolution),
+		errors.Is(err, sudoku.ErrLevelNotReached):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, sudoku.ErrInvalidDimensions), errors.Is(err, sudoku.ErrOutOfBounds), errors.Is(err, sudoku.ErrInvalidSymbol),
+		errors.Is(err, sudoku.ErrInvalidCage), errors.Is(err, sudoku.ErrInvalidRegion), errors.Is(err, sudoku.ErrInvalidConstraint),
+		errors.Is(err, sudoku.ErrInvalidOverlap), errors.Is(err, sudoku.ErrInvalidVariant), errors.Is(err, sudoku.ErrUnknownSolver):
+		return http.StatusBadRequest
+	}
+	return defaultStatus
+}
+
+// problem is the body of the error responses, see RFC 7807
+type problem struct {
+	Type           string             `json:"type"`
+	Title          string             `json:"title"`
+	Status         int                `json:"status"`
+	Detail         string             `json:"detail"`
+	InvalidSymbols []sudoku.Candidate `json:"invalidSymbols,omitempty"`
+	Conflicts      []sudoku.Conflict  `json:"conflicts,omitempty"`
+	CageSums       []sudoku.CageSum   `json:"cageSums,omitempty"`
+	Violations     []sudoku.Violation `json:"violations,omitempty"`
+	Closest        *sudoku.Difficulty `json:"closest,omitempty"` // grade of the closest puzzle to the requested level
+}
+
+// writeError responds with an application/problem+json body describing err,
+// the status code is given by errorStatus(err, defaultStatus)
+func writeError(w http.ResponseWriter, err error, defaultStatus int) {
+	status := errorStatus(err, defaultStatus)
+	p := problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: err.Error()}
+	var verr *sudoku.ValidationError
+	if errors.As(err, &verr) {
+		p.InvalidSymbols, p.Conflicts, p.CageSums, p.Violations = verr.InvalidSymbols, verr.Conflicts, verr.CageSums, verr.Violations
+	}
+	var lerr *sudoku.LevelError
+	if errors.As(err, &lerr) {
+		p.Closest = lerr.Closest
+	}
+
+	res, err := json.Marshal(p)
+	if err != nil {
+		log.Errorf("error marshalling the error response: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(res)
+}
+
 func homeHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Welcome to the Sudoku REST API v0.0.1"))
 }
@@ -45,40 +111,123 @@ func sudokuGeneratorHandler(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 	pretty := params.Get("pretty")
 	level := params.Get("level")
+	unique := params.Get("unique")
+	graded := params.Get("graded")
+	symbols := params.Get("symbols")
+	variant := params.Get("variant")
+	killer := params.Get("killer")
+	inequalities := params.Get("inequalities")
+	clues := params.Get("clues")
+	jigsaw := params.Get("jigsaw")
+	hyper := params.Get("hyper")
+	constraints := params.Get("constraints")
 
 	var result error
 	size, err := strconv.Atoi(params.Get("size"))
 	if err != nil {
 		result = multierror.Append(result, err)
 	}
-	partitionWidth, err := strconv.Atoi(params.Get("partitionWidth"))
+	seed, err := getSeed(params)
 	if err != nil {
 		result = multierror.Append(result, err)
 	}
-	partitionHeight, err := strconv.Atoi(params.Get("partitionHeight"))
-	if err != nil {
-		result = multierror.Append(result, err)
+	// the regions of a jigsaw sudoku replace the partitions
+	var partitionWidth, partitionHeight int
+	if jigsaw != "true" {
+		partitionWidth, err = strconv.Atoi(params.Get("partitionWidth"))
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+		partitionHeight, err = strconv.Atoi(params.Get("partitionHeight"))
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
 	}
 
 	if result != nil {
 		log.Errorf("error validating request params: %v", result)
-		http.Error(w, result.Error(), http.StatusBadRequest)
+		writeError(w, result, http.StatusBadRequest)
+		return
 	}
 
-	sG, err := sudoku.GenerateSudokuGrid(size, partitionWidth, partitionHeight)
+	symbols, err = sudoku.SymbolSet(symbols, size)
 	if err != nil {
-		log.Errorf("error generating sudoku grid: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Errorf("error validating request params: %v", err)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	err = sG.SetGridToLevel(level)
+	// the grid keeps drawing from the seed as it is turned into a puzzle
+	genOpts := []sudoku.GeneratorOption{sudoku.WithSymbols(symbols), sudoku.WithVariant(variant), sudoku.WithSeed(seed)}
+	if jigsaw == "true" {
+		genOpts = append(genOpts, sudoku.WithJigsaw())
+	}
+	if hyper == "true" {
+		genOpts = append(genOpts, sudoku.WithHyper())
+	}
+	// only the constraints applying to the whole grid are named here, the others need cells given in a body
+	if constraints != "" {
+		for _, typ := range strings.Split(constraints, ",") {
+			c, err := sudoku.NewConstraint(typ)
+			if err != nil {
+				log.Errorf("error validating request params: %v", err)
+				writeError(w, err, http.StatusBadRequest)
+				return
+			}
+			genOpts = append(genOpts, sudoku.WithConstraints(c))
+		}
+	}
+	sG, err := sudoku.GenerateSudokuGridContext(r.Context(), size, partitionWidth, partitionHeight, genOpts...)
 	if err != nil {
-		log.Errorf("error setting the grid to the difficulty level: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Errorf("error generating sudoku grid: %v", err)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
+	if killer == "true" {
+		// a killer sudoku has no givens, the cages alone make its solution unique
+		err = sG.SetGridToKillerContext(r.Context())
+		if err != nil {
+			log.Errorf("error turning the grid into a killer sudoku: %v", err)
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+	} else if inequalities == "true" {
+		// a comparison sudoku gives the values needed on top of the signs to make its solution unique
+		err = sG.SetGridToInequalitiesContext(r.Context())
+		if err != nil {
+			log.Errorf("error turning the grid into a comparison sudoku: %v", err)
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+	} else if clues != "" {
+		// the clues of the given types are written around the grid, only the values they leave ambiguous are given
+		err = sG.SetGridToCluesContext(r.Context(), strings.Split(clues, ",")...)
+		if err != nil {
+			log.Errorf("error giving clues to the grid: %v", err)
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+	} else {
+		var opts []sudoku.GeneratorOption
+		if unique == "true" {
+			opts = append(opts, sudoku.WithUniqueSolution())
+		}
+		// the puzzles of a level are graded by the solving techniques they require unless told otherwise, small grids
+		// cannot reach every level
+		if level != "" && graded != "false" {
+			opts = append(opts, sudoku.WithGradedDifficulty(sudoku.DefaultGradingAttempts))
+		}
+
+		err = sG.SetGridToLevelContext(r.Context(), level, opts...)
+		if err != nil {
+			log.Errorf("error setting the grid to the difficulty level: %v", err)
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set(seedHeader, strconv.FormatInt(seed, 10))
 	var res []byte
 	if pretty == "true" {
 		res = []byte(sG.ToStringPrettify())
@@ -86,30 +235,25 @@ func sudokuGeneratorHandler(w http.ResponseWriter, r *http.Request) {
 		res, err = json.Marshal(sG)
 		if err != nil {
 			log.Errorf("error marshalling the response: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err, http.StatusInternalServerError)
 			return
 		}
 	}
 	w.Write(res)
 }
 
-func sudokuSolverHandler(w http.ResponseWriter, r *http.Request) {
-	params := r.URL.Query()
-	pretty := params.Get("pretty")
+// readSudokuGrid decodes and validates the SudokuGrid sent in the request body
+func readSudokuGrid(r *http.Request) (*sudoku.SudokuGrid, error) {
 	defer r.Body.Close()
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		log.Errorf("error reading the body: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return nil, fmt.Errorf("error reading the body: %w", err)
 	}
 
 	sG := sudoku.SudokuGrid{}
 	err = json.Unmarshal(body, &sG)
 	if err != nil {
-		log.Errorf("error unmarshalling the body: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return nil, fmt.Errorf("error unmarshalling the body: %w", err)
 	}
 
 	b, err := json.Marshal(sG)
@@ -120,47 +264,461 @@ func sudokuSolverHandler(w http.ResponseWriter, r *http.Request) {
 	log.Debugf("body: %v", string(b))
 
 	if err = sG.Valid(); err != nil {
+		return nil, fmt.Errorf("error validating the sudoku grid: %w", err)
+	}
+	return &sG, nil
+}
+
+// getSeed returns the seed given by the seed query parameter, a new one if unset, so that every puzzle generated
+// can be generated again
+func getSeed(params url.Values) (int64, error) {
+	if params.Get("seed") == "" {
+		return time.Now().UnixNano(), nil
+	}
+	seed, err := strconv.ParseInt(params.Get("seed"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid seed: %w", err)
+	}
+	return seed, nil
+}
+
+// seedHeader is the response header echoing the seed a puzzle was generated from, for the human readable output
+const seedHeader = "X-Sudoku-Seed"
+
+// getSolver returns the name and the engine selected by the solver query parameter, sudoku.DefaultSolver if unset
+func getSolver(params url.Values) (string, sudoku.Solver, error) {
+	name := params.Get("solver")
+	if name == "" {
+		name = sudoku.DefaultSolver
+	}
+	solver, err := sudoku.GetSolver(name)
+	return name, solver, err
+}
+
+type solverResponse struct {
+	Solver   string             `json:"solver"`
+	Stats    sudoku.Stats       `json:"stats"`
+	Solution *sudoku.SudokuGrid `json:"solution"`
+}
+
+func sudokuSolverHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	pretty := params.Get("pretty")
+
+	name, solver, err := getSolver(params)
+	if err != nil {
+		log.Errorf("error validating request params: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	sG, err := readSudokuGrid(r)
+	if err != nil {
+		log.Error(err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	// the variant parameter takes precedence over the one of the body
+	if variant := params.Get("variant"); variant != "" {
+		sG.Variant = variant
+	}
+	if err = sG.Validate(); err != nil {
 		log.Errorf("error validating the sudoku grid: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
-	if err = sG.Solve(); err != nil {
+
+	stats, err := sG.SolveWith(r.Context(), solver)
+	if err != nil {
 		log.Errorf("error solving the sudoku puzzle: %v", err)
-		w.Write([]byte(fmt.Sprintf("error solving the sudoku puzzle: %v", err)))
+		writeError(w, err, http.StatusInternalServerError)
 		return
 	}
+	log.Debugf("solved the sudoku puzzle using %s: %+v", name, stats)
 
 	var res []byte
 	if pretty == "true" {
 		w.Header().Set("Content-Type", "plain/text")
-		res = []byte(sG.ToStringPrettify())
+		res = []byte(fmt.Sprintf("%s\nsolver: %s, nodes: %d, backtracks: %d\n", sG.ToStringPrettify(), name, stats.Nodes, stats.Backtracks))
 	} else {
 		w.Header().Set("Content-Type", "application/json")
-		res, err = json.Marshal(sG)
+		res, err = json.Marshal(solverResponse{Solver: name, Stats: stats, Solution: sG})
+		if err != nil {
+			log.Errorf("error marshalling the response: %v", err)
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Write(res)
+}
+
+type uniquenessResponse struct {
+	Status    string               `json:"status"`
+	Solutions []*sudoku.SudokuGrid `json:"solutions"`
+}
+
+func sudokuUniquenessHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	pretty := params.Get("pretty")
+
+	_, solver, err := getSolver(params)
+	if err != nil {
+		log.Errorf("error validating request params: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	sG, err := readSudokuGrid(r)
+	if err != nil {
+		log.Error(err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err = sG.Validate(); err != nil {
+		log.Errorf("error validating the sudoku grid: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	// two solutions are enough to tell a unique puzzle apart, and serve as a witness otherwise
+	solutions, _, err := sG.FindSolutionsWith(r.Context(), solver, 2)
+	if err != nil {
+		log.Errorf("error counting the solutions of the sudoku puzzle: %v", err)
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+	resp := uniquenessResponse{Solutions: solutions}
+	switch len(solutions) {
+	case 0:
+		resp.Status = "none"
+	case 1:
+		resp.Status = "unique"
+	default:
+		resp.Status = "multiple"
+	}
+
+	var res []byte
+	if pretty == "true" {
+		w.Header().Set("Content-Type", "plain/text")
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s\n", resp.Status)
+		for _, solution := range resp.Solutions {
+			fmt.Fprintf(&b, "\n%s", solution.ToStringPrettify())
+		}
+		res = []byte(b.String())
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		res, err = json.Marshal(resp)
+		if err != nil {
+			log.Errorf("error marshalling the response: %v", err)
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Write(res)
+}
+
+func sudokuGradeHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	pretty := params.Get("pretty")
+
+	sG, err := readSudokuGrid(r)
+	if err != nil {
+		log.Error(err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	d, err := sudoku.GradeContext(r.Context(), sG)
+	if err != nil {
+		log.Errorf("error grading the sudoku puzzle: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var res []byte
+	if pretty == "true" {
+		w.Header().Set("Content-Type", "plain/text")
+		var b strings.Builder
+		fmt.Fprintf(&b, "level: %s, score: %d\n", d.Level, d.Score)
+		for _, t := range sudoku.Techniques() {
+			if n := d.Techniques[t]; n > 0 {
+				fmt.Fprintf(&b, "%s: %d\n", t, n)
+			}
+		}
+		res = []byte(b.String())
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		res, err = json.Marshal(d)
+		if err != nil {
+			log.Errorf("error marshalling the response: %v", err)
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Write(res)
+}
+
+func sudokuHintHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	pretty := params.Get("pretty")
+
+	sG, err := readSudokuGrid(r)
+	if err != nil {
+		log.Error(err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	hint, err := sG.HintContext(r.Context())
+	if err != nil {
+		log.Errorf("error finding a hint for the sudoku puzzle: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var res []byte
+	if pretty == "true" {
+		w.Header().Set("Content-Type", "plain/text")
+		var b strings.Builder
+		switch {
+		case hint.Solved:
+			fmt.Fprintf(&b, "the puzzle is solved\n")
+		case len(hint.Conflicts) > 0:
+			fmt.Fprintf(&b, "mistakes were made in the cells:")
+			for _, c := range hint.Conflicts {
+				fmt.Fprintf(&b, " r%dc%d", c.Row+1, c.Col+1)
+			}
+			fmt.Fprintf(&b, "\n")
+		case hint.Step != nil:
+			fmt.Fprintf(&b, "%s: %s\n", hint.Step.Technique, hint.Step.Description)
+		default:
+			fmt.Fprintf(&b, "no known technique makes progress\n")
+		}
+		res = []byte(b.String())
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		res, err = json.Marshal(hint)
+		if err != nil {
+			log.Errorf("error marshalling the response: %v", err)
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Write(res)
+}
+
+type candidatesResponse struct {
+	Candidates [][][]rune    `json:"candidates"`
+	Steps      []sudoku.Step `json:"steps,omitempty"`
+}
+
+func sudokuCandidatesHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	pretty := params.Get("pretty")
+	reduce := params.Get("reduce")
+
+	sG, err := readSudokuGrid(r)
+	if err != nil {
+		log.Error(err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err = sG.Validate(); err != nil {
+		log.Errorf("error validating the sudoku grid: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var resp candidatesResponse
+	if reduce == "true" {
+		resp.Candidates, resp.Steps, err = sG.ReduceCandidates()
+		if err != nil {
+			log.Errorf("error reducing the candidates of the sudoku puzzle: %v", err)
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+	} else {
+		resp.Candidates = sG.Candidates()
+	}
+
+	var res []byte
+	if pretty == "true" {
+		w.Header().Set("Content-Type", "plain/text")
+		var b strings.Builder
+		for i := range resp.Candidates {
+			for j, candidates := range resp.Candidates[i] {
+				if sG.Grid[i][j] == sudoku.EMPTY_CELL {
+					fmt.Fprintf(&b, "r%dc%d: %s\n", i+1, j+1, strings.Join(strings.Split(string(candidates), ""), " "))
+				}
+			}
+		}
+		for _, step := range resp.Steps {
+			fmt.Fprintf(&b, "%s: %s\n", step.Technique, step.Description)
+		}
+		res = []byte(b.String())
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		res, err = json.Marshal(resp)
+		if err != nil {
+			log.Errorf("error marshalling the response: %v", err)
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Write(res)
+}
+
+func samuraiGeneratorHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	pretty := params.Get("pretty")
+	level := params.Get("level")
+	symbols := params.Get("symbols")
+	variant := params.Get("variant")
+
+	// the grids of a samurai sudoku are 9x9 with 3x3 subgrids unless told otherwise
+	size, partitionWidth, partitionHeight := 9, 3, 3
+	var result error
+	for name, value := range map[string]*int{"size": &size, "partitionWidth": &partitionWidth, "partitionHeight": &partitionHeight} {
+		if params.Get(name) == "" {
+			continue
+		}
+		n, err := strconv.Atoi(params.Get(name))
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+		*value = n
+	}
+	seed, err := getSeed(params)
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+	if result != nil {
+		log.Errorf("error validating request params: %v", result)
+		writeError(w, result, http.StatusBadRequest)
+		return
+	}
+
+	symbols, err = sudoku.SymbolSet(symbols, size)
+	if err != nil {
+		log.Errorf("error validating request params: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	m, err := sudoku.GenerateSamuraiContext(r.Context(), size, partitionWidth, partitionHeight, sudoku.WithSymbols(symbols), sudoku.WithVariant(variant), sudoku.WithSeed(seed))
+	if err != nil {
+		log.Errorf("error generating samurai sudoku: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	// the solution of a samurai sudoku is always unique, the grids are too large to be graded
+	err = m.SetToLevelContext(r.Context(), level, sudoku.WithUniqueSolution())
+	if err != nil {
+		log.Errorf("error setting the samurai sudoku to the difficulty level: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set(seedHeader, strconv.FormatInt(seed, 10))
+	var res []byte
+	if pretty == "true" {
+		res = []byte(m.ToStringPrettify())
+	} else {
+		res, err = json.Marshal(m)
+		if err != nil {
+			log.Errorf("error marshalling the response: %v", err)
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Write(res)
+}
+
+type samuraiSolverResponse struct {
+	Solver   string            `json:"solver"`
+	Stats    sudoku.Stats      `json:"stats"`
+	Solution *sudoku.MultiGrid `json:"solution"`
+}
+
+func samuraiSolverHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	pretty := params.Get("pretty")
+
+	name, solver, err := getSolver(params)
+	if err != nil {
+		log.Errorf("error validating request params: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Errorf("error reading the body: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	m := sudoku.MultiGrid{}
+	if err = json.Unmarshal(body, &m); err != nil {
+		log.Errorf("error unmarshalling the body: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err = m.Validate(); err != nil {
+		log.Errorf("error validating the samurai sudoku: %v", err)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	stats, err := m.SolveWith(r.Context(), solver)
+	if err != nil {
+		log.Errorf("error solving the samurai sudoku: %v", err)
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+	log.Debugf("solved the samurai sudoku using %s: %+v", name, stats)
+
+	var res []byte
+	if pretty == "true" {
+		w.Header().Set("Content-Type", "plain/text")
+		res = []byte(fmt.Sprintf("%s\nsolver: %s, nodes: %d, backtracks: %d\n", m.ToStringPrettify(), name, stats.Nodes, stats.Backtracks))
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		res, err = json.Marshal(samuraiSolverResponse{Solver: name, Stats: stats, Solution: &m})
 		if err != nil {
 			log.Errorf("error marshalling the response: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err, http.StatusInternalServerError)
 			return
 		}
 	}
 	w.Write(res)
 }
 
-func SetupHandlers(r *mux.Router) {
+func SetupHandlers(r *mux.Router, cfg config.Config) {
 	publicMiddleware := []middleware.Middleware{
 		middleware.LogMiddleware,
+		middleware.TimeoutMiddleware(cfg.MaxSolveDuration),
 	}
 	// TODO: add support for authentication => privateMiddleware
 	r.HandleFunc("/", middleware.Chain(homeHandler, publicMiddleware...)).Methods("GET")
 	r.HandleFunc("/sudoku", middleware.Chain(sudokuSolverHandler, publicMiddleware...)).Methods("POST")
 	r.HandleFunc("/sudoku", middleware.Chain(sudokuGeneratorHandler, publicMiddleware...)).Methods("GET")
+	r.HandleFunc("/sudoku/daily", middleware.Chain(sudokuDailyHandler, publicMiddleware...)).Methods("GET")
+	r.HandleFunc("/sudoku/uniqueness", middleware.Chain(sudokuUniquenessHandler, publicMiddleware...)).Methods("POST")
+	r.HandleFunc("/sudoku/grade", middleware.Chain(sudokuGradeHandler, publicMiddleware...)).Methods("POST")
+	r.HandleFunc("/sudoku/hint", middleware.Chain(sudokuHintHandler, publicMiddleware...)).Methods("POST")
+	r.HandleFunc("/sudoku/candidates", middleware.Chain(sudokuCandidatesHandler, publicMiddleware...)).Methods("POST")
+	r.HandleFunc("/samurai", middleware.Chain(samuraiSolverHandler, publicMiddleware...)).Methods("POST")
+	r.HandleFunc("/samurai", middleware.Chain(samuraiGeneratorHandler, publicMiddleware...)).Methods("GET")
 }
 
 func StartServer(cfg config.Config) {
 	initLogger(cfg)
 
 	r := mux.NewRouter()
-	SetupHandlers(r)
+	SetupHandlers(r, cfg)
 
 	log.Printf("Server listening on port %d", cfg.Port)
 
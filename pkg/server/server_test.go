@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	// duplicatesGrid repeats 1 in the first row
+	duplicatesGrid = `{"size":4,"partitionWidth":2,"partitionHeight":2,"grid":[[49,46,46,49],[46,46,46,46],[46,46,46,46],[46,46,46,46]]}`
+	// foreignSymbolGrid holds a 9, which is not a symbol of a 4x4 grid
+	foreignSymbolGrid = `{"size":4,"partitionWidth":2,"partitionHeight":2,"grid":[[49,46,46,57],[46,46,46,46],[46,46,46,46],[46,46,46,46]]}`
+)
+
+// post calls the handler with the given body
+func post(handler http.HandlerFunc, target, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+// problemOf decodes the error response
+func problemOf(w *httptest.ResponseRecorder) problem {
+	Expect(w.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+	var p problem
+	Expect(json.Unmarshal(w.Body.Bytes(), &p)).To(Succeed())
+	return p
+}
+
+var _ = Describe("Grid validation", func() {
+	It("reports the conflicts of the givens of the uniqueness endpoint", func() {
+		w := post(sudokuUniquenessHandler, "/sudoku/uniqueness", duplicatesGrid)
+		Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+		p := problemOf(w)
+		Expect(p.Status).To(Equal(http.StatusUnprocessableEntity))
+		Expect(p.Conflicts).NotTo(BeEmpty())
+
+		w = post(sudokuUniquenessHandler, "/sudoku/uniqueness", foreignSymbolGrid)
+		Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(problemOf(w).InvalidSymbols).To(HaveLen(1))
+	})
+
+	It("reports the conflicts of the givens of the candidates endpoint", func() {
+		for _, target := range []string{"/sudoku/candidates", "/sudoku/candidates?reduce=true"} {
+			w := post(sudokuCandidatesHandler, target, duplicatesGrid)
+			Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(problemOf(w).Conflicts).NotTo(BeEmpty())
+
+			w = post(sudokuCandidatesHandler, target, foreignSymbolGrid)
+			Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(problemOf(w).InvalidSymbols).To(HaveLen(1))
+		}
+	})
+
+	It("still answers valid grids", func() {
+		valid := `{"size":4,"partitionWidth":2,"partitionHeight":2,"grid":[[49,46,46,52],[46,46,49,46],[50,46,46,46],[52,46,50,46]]}`
+		Expect(post(sudokuUniquenessHandler, "/sudoku/uniqueness", valid).Code).To(Equal(http.StatusOK))
+		Expect(post(sudokuCandidatesHandler, "/sudoku/candidates", valid).Code).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,113 @@
+package sudoku
+
+// basicEliminations are the techniques applied by ReduceCandidates, they only remove candidates
+var basicEliminations = map[Technique]bool{
+	NakedPair:        true,
+	NakedTriple:      true,
+	HiddenPair:       true,
+	HiddenTriple:     true,
+	PointingPair:     true,
+	BoxLineReduction: true,
+}
+
+// Candidates returns the values each empty cell may hold given the values of its row, column and subgrid,
+// restricted to the pencil marks of the cell if any. The values follow the order of the symbols of the grid,
+// filled cells have no candidate.
+func (sG *SudokuGrid) Candidates() [][][]rune {
+	values := sG.symbols()
+	res := make([][][]rune, sG.Size)
+	for i := 0; i < sG.Size; i++ {
+		res[i] = make([][]rune, sG.Size)
+		for j := 0; j < len(sG.Grid[i]); j++ {
+			if sG.Grid[i][j] != EMPTY_CELL {
+				continue
+			}
+			marks := sG.pencilMarks(i, j)
+			for _, val := range values {
+				if sG.canSet(i, j, val) && (marks == nil || marks[val]) {
+					res[i][j] = append(res[i][j], val)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// ReduceCandidates is like Candidates but also applies the basic elimination techniques (naked and hidden
+// pairs and triples, pointing pairs and box-line reductions) until none of them applies.
+// The steps applied are returned along with the candidates, an error is returned if the puzzle has no solution.
+func (sG *SudokuGrid) ReduceCandidates() ([][][]rune, []Step, error) {
+	lg, err := newLogic(sG)
+	if err != nil {
+		return nil, nil, err
+	}
+	for cell := range lg.candidates {
+		marks := sG.pencilMarks(cell/lg.size, cell%lg.size)
+		if lg.values[cell] != -1 || marks == nil {
+			continue
+		}
+		var mask uint64
+		for v, symbol := range lg.symbols {
+			if marks[symbol] {
+				mask |= 1 << uint(v)
+			}
+		}
+		lg.candidates[cell] &= mask
+	}
+	if lg.contradiction() {
+		return nil, nil, ErrNoSolution
+	}
+
+	steps := []Step{}
+	for progress := true; progress; {
+		progress = false
+		for _, t := range techniques {
+			if !basicEliminations[t.name] {
+				continue
+			}
+			step := t.apply(lg)
+			if step == nil {
+				continue
+			}
+			if lg.contradiction() {
+				return nil, nil, ErrNoSolution
+			}
+			steps = append(steps, *step)
+			progress = true
+			break
+		}
+	}
+
+	index := make(map[rune]int, len(lg.symbols))
+	for v, symbol := range lg.symbols {
+		index[symbol] = v
+	}
+	symbols := sG.symbols()
+	res := make([][][]rune, lg.size)
+	for i := range res {
+		res[i] = make([][]rune, lg.size)
+	}
+	for cell, v := range lg.values {
+		if v != -1 {
+			continue
+		}
+		for _, symbol := range symbols {
+			if lg.candidates[cell]&(1<<uint(index[symbol])) != 0 {
+				res[cell/lg.size][cell%lg.size] = append(res[cell/lg.size][cell%lg.size], symbol)
+			}
+		}
+	}
+	return res, steps, nil
+}
+
+// pencilMarks returns the set of the pencil marks of the cell (x, y), nil if the player gave none
+func (sG *SudokuGrid) pencilMarks(x, y int) map[rune]bool {
+	if x >= len(sG.PencilMarks) || y >= len(sG.PencilMarks[x]) || len(sG.PencilMarks[x][y]) == 0 {
+		return nil
+	}
+	marks := make(map[rune]bool, len(sG.PencilMarks[x][y]))
+	for _, val := range sG.PencilMarks[x][y] {
+		marks[val] = true
+	}
+	return marks
+}
@@ -0,0 +1,85 @@
+package sudoku
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Candidates", func() {
+	It("derives the candidates of the empty cells from their units", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		candidates := sG.Candidates()
+		Expect(candidates).To(HaveLen(9))
+		// r1c1 sees 2, 3 and 6 in its row, 7, 8 and 9 in its column and 1 in its subgrid
+		Expect(candidates[0][0]).To(Equal([]rune("45")))
+		// r1c3 holds a value
+		Expect(candidates[0][2]).To(BeEmpty())
+	})
+
+	It("keeps the pencil marks of the player", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		sG.PencilMarks = make([][][]rune, 9)
+		for i := range sG.PencilMarks {
+			sG.PencilMarks[i] = make([][]rune, 9)
+		}
+		sG.PencilMarks[0][0] = []rune("56")
+		candidates := sG.Candidates()
+		Expect(candidates[0][0]).To(Equal([]rune("5")))
+		Expect(candidates[0][1]).NotTo(BeEmpty())
+	})
+
+	It("removes candidates with basic techniques", func() {
+		sG := parseGrid(9, 3, mediumPuzzle)
+		solution := sG.Clone()
+		Expect(solution.Solve()).To(Succeed())
+
+		basic := sG.Candidates()
+		reduced, steps, err := sG.ReduceCandidates()
+		Expect(err).To(BeNil())
+		Expect(steps).NotTo(BeEmpty())
+
+		removed := 0
+		for i := range reduced {
+			for j := range reduced[i] {
+				for _, val := range reduced[i][j] {
+					Expect(basic[i][j]).To(ContainElement(val))
+				}
+				if sG.Grid[i][j] == EMPTY_CELL {
+					Expect(reduced[i][j]).To(ContainElement(solution.Grid[i][j]))
+				}
+				removed += len(basic[i][j]) - len(reduced[i][j])
+			}
+		}
+		Expect(removed).To(BeNumerically(">", 0))
+		for _, step := range steps {
+			Expect(basicEliminations[step.Technique]).To(BeTrue())
+			Expect(step.Placements).To(BeEmpty())
+		}
+	})
+
+	It("returns an error when the pencil marks rule out every value of a cell", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		sG.PencilMarks = make([][][]rune, 9)
+		for i := range sG.PencilMarks {
+			sG.PencilMarks[i] = make([][]rune, 9)
+		}
+		sG.PencilMarks[0][0] = []rune("9")
+		_, _, err := sG.ReduceCandidates()
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("serializes the pencil marks", func() {
+		sG := &SudokuGrid{}
+		err := json.Unmarshal([]byte(`{"size":4,"partitionWidth":2,"partitionHeight":2,"grid":[[49,46,46,52],[46,52,49,46],[50,46,46,51],[52,46,50,46]],`+
+			`"pencilMarks":[[[],[50,51],[],[]],[[],[],[],[]],[[],[],[],[]],[[],[],[],[]]]}`), sG)
+		Expect(err).To(BeNil())
+		Expect(sG.Candidates()[0][1]).To(Equal([]rune("23")))
+		Expect(sG.Clone().PencilMarks).To(Equal(sG.PencilMarks))
+
+		err = json.Unmarshal([]byte(`{"size":4,"partitionWidth":2,"partitionHeight":2,"grid":[[49,46,46,52],[46,52,49,46],[50,46,46,51],[52,46,50,46]],`+
+			`"pencilMarks":[[[],[50,51],[],[]]]}`), sG)
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,355 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Types of the clues written outside of the grid
+const (
+	// ClueSandwich is the sum of the values between the smallest and the largest value of the row or the column
+	ClueSandwich = "sandwich"
+	// ClueXSum is the sum of the first N values seen from the side, N being the first value itself
+	ClueXSum = "x-sum"
+	// ClueSkyscraper is the number of values seen from the side, a value hiding the smaller ones behind it
+	ClueSkyscraper = "skyscraper"
+)
+
+// Sides of the grid a clue is written on
+const (
+	SideTop    = "top"
+	SideRight  = "right"
+	SideBottom = "bottom"
+	SideLeft   = "left"
+)
+
+// Clue is a number written outside of the grid about the row or the column it faces, such as the sandwich sums,
+// the X-sums or the skyscrapers. It is enforced as a Constraint, see ClueSandwich, ClueXSum and ClueSkyscraper.
+type Clue struct {
+	Kind  string `json:"type"`
+	Side  string `json:"side"`  // SideTop or SideBottom for a column, SideLeft or SideRight for a row
+	Index int    `json:"index"` // index of the row or the column, from 0
+	Value int    `json:"value"`
+}
+
+func (c Clue) Type() string { return c.Kind }
+
+func (c Clue) String() string {
+	line := "row"
+	if c.Side == SideTop || c.Side == SideBottom {
+		line = "column"
+	}
+	return fmt.Sprintf("%s %d from the %s of %s %d", c.Kind, c.Value, c.Side, line, c.Index+1)
+}
+
+// Valid checks the type, the side and the line of the clue, and that its value may be reached in the grid
+func (c Clue) Valid(size int) error {
+	var min, max int
+	switch c.Kind {
+	case ClueSandwich:
+		// the values from 2 to size - 1 may all lie in between
+		min, max = 0, size*(size+1)/2-1-size
+	case ClueXSum:
+		min, max = 1, size*(size+1)/2
+	case ClueSkyscraper:
+		min, max = 1, size
+	default:
+		return fmt.Errorf("%w: the type of a clue is %s, %s or %s, not %q", ErrInvalidConstraint, ClueSandwich, ClueXSum, ClueSkyscraper, c.Kind)
+	}
+	switch c.Side {
+	case SideTop, SideRight, SideBottom, SideLeft:
+	default:
+		return fmt.Errorf("%w: the side of a clue is %s, %s, %s or %s, not %q", ErrInvalidConstraint, SideTop, SideRight, SideBottom, SideLeft, c.Side)
+	}
+	if c.Index < 0 || c.Index >= size {
+		return fmt.Errorf("%w: the %s clue faces line %d of a grid of %d lines", ErrInvalidConstraint, c.Kind, c.Index+1, size)
+	}
+	if c.Value < min || c.Value > max {
+		return fmt.Errorf("%w: the value of a %s clue is between %d and %d, not %d", ErrInvalidConstraint, c.Kind, min, max, c.Value)
+	}
+	return nil
+}
+
+// cells returns the cells of the line the clue faces, starting from its side
+func (c Clue) cells(size int) []Cell {
+	res := make([]Cell, size)
+	for i := range res {
+		switch c.Side {
+		case SideTop:
+			res[i] = Cell{Row: i, Col: c.Index}
+		case SideBottom:
+			res[i] = Cell{Row: size - 1 - i, Col: c.Index}
+		case SideLeft:
+			res[i] = Cell{Row: c.Index, Col: i}
+		default:
+			res[i] = Cell{Row: c.Index, Col: size - 1 - i}
+		}
+	}
+	return res
+}
+
+func (c Clue) Related(size int, cell Cell) []Cell {
+	cells := c.cells(size)
+	if indexOf(cells, cell) == -1 {
+		return nil
+	}
+	return others(cells, cell)
+}
+
+// Allows checks the value of the clue can still be reached given the filled cells of the line, the empty cells
+// holding the values missing from it
+func (c Clue) Allows(s *State, cell Cell, v int) bool {
+	cells := c.cells(s.Size)
+	i := indexOf(cells, cell)
+	if i == -1 {
+		return true
+	}
+	values := make([]int, len(cells))
+	for j, other := range cells {
+		values[j] = s.Value(other)
+	}
+	values[i] = v
+	switch c.Kind {
+	case ClueSandwich:
+		return c.sandwichAllows(values)
+	case ClueXSum:
+		return c.xSumAllows(values)
+	default:
+		return c.skyscraperAllows(values)
+	}
+}
+
+func (Clue) Prune(s *State) bool { return true }
+
+// sumWithin returns true if the values of the line from start to end, excluded, may add up to the value of the
+// clue, the empty cells holding distinct numbers missing from the line and allowed by ok
+func (c Clue) sumWithin(values []int, start, end int, ok func(n int) bool) bool {
+	used := make([]bool, len(values)+1)
+	sum, empty := 0, 0
+	for j, w := range values {
+		if w != -1 {
+			used[w+1] = true
+			if j >= start && j < end {
+				sum += w + 1
+			}
+		} else if j >= start && j < end {
+			empty++
+		}
+	}
+	low, high := sum, sum
+	for n, k := 1, 0; n <= len(values) && k < empty; n++ {
+		if !used[n] && ok(n) {
+			low, k = low+n, k+1
+		}
+	}
+	for n, k := len(values), 0; n >= 1 && k < empty; n-- {
+		if !used[n] && ok(n) {
+			high, k = high+n, k+1
+		}
+	}
+	return low <= c.Value && c.Value <= high
+}
+
+// sandwichAllows checks the sum between the smallest and the largest value once both are placed
+func (c Clue) sandwichAllows(values []int) bool {
+	low, high := -1, -1
+	for j, w := range values {
+		switch w {
+		case 0:
+			low = j
+		case len(values) - 1:
+			high = j
+		}
+	}
+	if low == -1 || high == -1 {
+		return true
+	}
+	if low > high {
+		low, high = high, low
+	}
+	return c.sumWithin(values, low+1, high, func(n int) bool { return n > 1 && n < len(values) })
+}
+
+// xSumAllows checks the sum of the first cells once the first value is placed
+func (c Clue) xSumAllows(values []int) bool {
+	if values[0] == -1 {
+		return true
+	}
+	return c.sumWithin(values, 0, values[0]+1, func(n int) bool { return true })
+}
+
+// skyscraperAllows checks the number of values seen from the side: those seen up to the first empty cell are
+// counted, then the largest value is always seen and the values after it never are
+func (c Clue) skyscraperAllows(values []int) bool {
+	seen, highest, j := 0, -1, 0
+	for ; j < len(values) && values[j] != -1; j++ {
+		if values[j] > highest {
+			seen, highest = seen+1, values[j]
+		}
+	}
+	if highest == len(values)-1 {
+		return seen == c.Value
+	}
+	low, high := seen+1, seen
+	for ; j < len(values); j++ {
+		if values[j] == -1 || values[j] > highest {
+			high++
+		}
+		if values[j] == len(values)-1 {
+			break
+		}
+	}
+	return low <= c.Value && c.Value <= high
+}
+
+// value returns the value of the clue for the values of the line, which must all be filled
+func (c Clue) value(values []int) int {
+	switch c.Kind {
+	case ClueSandwich:
+		sum, in := 0, false
+		for _, w := range values {
+			if w == 0 || w == len(values)-1 {
+				if in {
+					return sum
+				}
+				in = true
+			} else if in {
+				sum += w + 1
+			}
+		}
+		return sum
+	case ClueXSum:
+		sum := 0
+		for _, w := range values[:values[0]+1] {
+			sum += w + 1
+		}
+		return sum
+	default:
+		seen, highest := 0, -1
+		for _, w := range values {
+			if w > highest {
+				seen, highest = seen+1, w
+			}
+		}
+		return seen
+	}
+}
+
+// validClues returns an error if a clue does not fit in the grid or is given twice on the same side of a line
+func (sG *SudokuGrid) validClues() error {
+	seen := map[Clue]bool{}
+	for i, c := range sG.Clues {
+		if err := c.Valid(sG.Size); err != nil {
+			return fmt.Errorf("clue %d (%s): %w", i+1, c.Kind, err)
+		}
+		key := c
+		key.Value = 0
+		if seen[key] {
+			return fmt.Errorf("%w: clue %d (%s) is given twice on the %s of line %d", ErrInvalidConstraint, i+1, c.Kind, c.Side, c.Index+1)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// clueLabels returns the clues to write on each side of the grid in layers indexed by row or column, the clues
+// given on the same side of a line going to the next layers, nil if there are none
+func (sG *SudokuGrid) clueLabels() map[string][][]string {
+	if len(sG.Clues) == 0 {
+		return nil
+	}
+	res := map[string][][]string{}
+	for _, c := range sG.Clues {
+		if c.Index < 0 || c.Index >= sG.Size {
+			continue
+		}
+		layers := res[c.Side]
+		i := 0
+		for i < len(layers) && layers[i][c.Index] != "" {
+			i++
+		}
+		if i == len(layers) {
+			layers = append(layers, make([]string, sG.Size))
+		}
+		layers[i][c.Index] = strconv.Itoa(c.Value)
+		res[c.Side] = layers
+	}
+	return res
+}
+
+// SetGridToClues turns the solved SudokuGrid into a puzzle given by clues outside of the grid: the clues of the
+// given types are set on the sides of every row and column, on the top and on the left only for the sandwiches
+// which read the same from both sides, all of them if no type is given. The values are then removed in a random
+// order as long as the puzzle keeps a unique solution, until maxHideFailures of them turn out to be needed. Grids
+// larger than maxUnguidedSize are rejected with ErrInvalidDimensions.
+func (sG *SudokuGrid) SetGridToClues(types ...string) error {
+	return sG.SetGridToCluesContext(context.Background(), types...)
+}
+
+// SetGridToCluesContext is like SetGridToClues but gives up with the context error once ctx is done, in which case
+// the SudokuGrid is left solved
+func (sG *SudokuGrid) SetGridToCluesContext(ctx context.Context, types ...string) error {
+	if len(sG.missingCells()) > 0 {
+		return errors.New("the grid must be solved to be given clues")
+	}
+	if sG.Size > maxUnguidedSize {
+		return fmt.Errorf("%w: puzzles given by clues are generated up to %dx%d", ErrInvalidDimensions, maxUnguidedSize, maxUnguidedSize)
+	}
+	if len(types) == 0 {
+		types = []string{ClueSandwich, ClueXSum, ClueSkyscraper}
+	}
+	solution := sG.Clone()
+	fail := func(err error) error {
+		sG.Clues = nil
+		sG.copyFrom(solution)
+		return err
+	}
+
+	sG.Clues = nil
+	s, _ := sG.state()
+	for _, typ := range types {
+		sides := []string{SideTop, SideRight, SideBottom, SideLeft}
+		if typ == ClueSandwich {
+			sides = []string{SideTop, SideLeft}
+		}
+		for _, side := range sides {
+			for i := 0; i < sG.Size; i++ {
+				c := Clue{Kind: typ, Side: side, Index: i}
+				values := make([]int, sG.Size)
+				for j, cell := range c.cells(sG.Size) {
+					values[j] = s.Value(cell)
+				}
+				c.Value = c.value(values)
+				sG.Clues = append(sG.Clues, c)
+			}
+		}
+	}
+	if err := sG.validClues(); err != nil {
+		return fail(err)
+	}
+
+	cells := sG.filledCells()
+	sG.random().Shuffle(len(cells), func(i, j int) { cells[i], cells[j] = cells[j], cells[i] })
+	failures := 0
+	for _, c := range cells {
+		if failures == maxHideFailures {
+			break
+		}
+		if err := contextError(ctx); err != nil {
+			return fail(err)
+		}
+		sG.Set(c.x, c.y, EMPTY_CELL)
+		unique, err := sG.uniqueWithin(ctx)
+		if err != nil {
+			return fail(err)
+		}
+		if !unique {
+			// the puzzle became ambiguous, or too hard to prove unique, give the value back
+			sG.Set(c.x, c.y, solution.Grid[c.x][c.y])
+			failures++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// clueValue returns the value of the clue in the solved SudokuGrid
+func clueValue(sG *SudokuGrid, c Clue) int {
+	s, _ := sG.state()
+	values := make([]int, sG.Size)
+	for i, cell := range c.cells(sG.Size) {
+		values[i] = s.Value(cell)
+	}
+	return c.value(values)
+}
+
+var _ = Describe("Clue", func() {
+	// the rows of the 4x4 pattern grid are 1234, 3412, 2341 and 4123
+	DescribeTable("reads the clues of a solved line from their side",
+		func(c Clue, expected int) {
+			Expect(clueValue(patternGrid(4, 2, 2), c)).To(Equal(expected))
+		},
+		Entry("sandwich", Clue{Kind: ClueSandwich, Side: SideLeft, Index: 0}, 5),
+		Entry("empty sandwich", Clue{Kind: ClueSandwich, Side: SideLeft, Index: 1}, 0),
+		Entry("sandwich in a column", Clue{Kind: ClueSandwich, Side: SideTop, Index: 1}, 3),
+		Entry("x-sum from the left", Clue{Kind: ClueXSum, Side: SideLeft, Index: 1}, 8),
+		Entry("x-sum from the right", Clue{Kind: ClueXSum, Side: SideRight, Index: 0}, 10),
+		Entry("x-sum from the bottom", Clue{Kind: ClueXSum, Side: SideBottom, Index: 2}, 6),
+		Entry("skyscraper from the left", Clue{Kind: ClueSkyscraper, Side: SideLeft, Index: 0}, 4),
+		Entry("skyscraper from the right", Clue{Kind: ClueSkyscraper, Side: SideRight, Index: 0}, 1),
+		Entry("skyscraper from the top", Clue{Kind: ClueSkyscraper, Side: SideTop, Index: 2}, 2),
+	)
+
+	DescribeTable("generates puzzles whose clues have a unique solution",
+		func(size, partitionWidth, partitionHeight int, types ...string) {
+			sG, err := GenerateSudokuGrid(size, partitionWidth, partitionHeight)
+			Expect(err).To(BeNil())
+			solution := sG.Clone()
+			Expect(sG.SetGridToClues(types...)).To(Succeed())
+
+			Expect(len(sG.filledCells())).To(BeNumerically("<", size*size))
+			Expect(sG.Validate()).To(Succeed())
+			kinds := map[string]bool{}
+			for _, c := range sG.Clues {
+				Expect(c.Value).To(Equal(clueValue(solution, c)))
+				kinds[c.Kind] = true
+			}
+			Expect(kinds).To(HaveLen(len(types)))
+			Expect(sG.IsUnique()).To(BeTrue())
+
+			for _, name := range Solvers() {
+				s, err := GetSolver(name)
+				Expect(err).To(BeNil())
+				puzzle := sG.Clone()
+				_, err = puzzle.SolveWith(context.Background(), s)
+				Expect(err).To(BeNil())
+				Expect(puzzle.Grid).To(Equal(solution.Grid))
+			}
+		},
+		Entry("4x4 sandwich", 4, 2, 2, ClueSandwich),
+		Entry("6x6 sandwich", 6, 3, 2, ClueSandwich),
+		Entry("6x6 x-sum", 6, 3, 2, ClueXSum),
+		Entry("6x6 skyscraper", 6, 3, 2, ClueSkyscraper),
+		Entry("9x9 sandwich and x-sum", 9, 3, 3, ClueSandwich, ClueXSum),
+	)
+
+	It("sets every type of clue by default", func() {
+		sG, err := GenerateSudokuGrid(4, 2, 2)
+		Expect(err).To(BeNil())
+		Expect(sG.SetGridToClues()).To(Succeed())
+		// the sandwiches on 2 sides, the others on 4 sides of the 4 rows and columns
+		Expect(sG.Clues).To(HaveLen(2*4 + 2*4*4))
+	})
+
+	It("leaves the grid solved once the context is done", func() {
+		sG, err := GenerateSudokuGrid(6, 3, 2)
+		Expect(err).To(BeNil())
+		solution := sG.Clone()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(sG.SetGridToCluesContext(ctx, ClueSkyscraper)).To(MatchError(ErrTimeout))
+		Expect(sG.Grid).To(Equal(solution.Grid))
+		Expect(sG.Clues).To(BeEmpty())
+	})
+
+	It("rejects grids too large to be generated in time", func() {
+		sG, err := GenerateSudokuGrid(12, 4, 3)
+		Expect(err).To(BeNil())
+		solution := sG.Clone()
+		Expect(sG.SetGridToClues(ClueXSum)).To(MatchError(ErrInvalidDimensions))
+		Expect(sG.Grid).To(Equal(solution.Grid))
+		Expect(sG.Clues).To(BeEmpty())
+	})
+
+	It("rejects a type of clue it does not know", func() {
+		sG, err := GenerateSudokuGrid(4, 2, 2)
+		Expect(err).To(BeNil())
+		solution := sG.Clone()
+		Expect(sG.SetGridToClues("killer")).To(MatchError(ErrInvalidConstraint))
+		Expect(sG.Grid).To(Equal(solution.Grid))
+	})
+
+	It("allows the values leaving the clue reachable", func() {
+		sG, err := New(4, 2, 2)
+		Expect(err).To(BeNil())
+		sG.Set(0, 0, '1')
+		s, _ := sG.state()
+
+		sandwich := Clue{Kind: ClueSandwich, Side: SideLeft, Index: 0, Value: 0}
+		Expect(sandwich.Allows(s, Cell{0, 1}, 3)).To(BeTrue())
+		// 2 and 3 would lie between 1 and 4
+		Expect(sandwich.Allows(s, Cell{0, 3}, 3)).To(BeFalse())
+		Expect(sandwich.Allows(s, Cell{1, 3}, 3)).To(BeTrue())
+
+		// the sum of the first 4 cells is 10
+		xSum := Clue{Kind: ClueXSum, Side: SideRight, Index: 1, Value: 10}
+		Expect(xSum.Allows(s, Cell{1, 3}, 3)).To(BeTrue())
+		Expect(xSum.Allows(s, Cell{1, 3}, 2)).To(BeFalse())
+
+		// the largest value hides the others from the left
+		skyscraper := Clue{Kind: ClueSkyscraper, Side: SideLeft, Index: 1, Value: 1}
+		Expect(skyscraper.Allows(s, Cell{1, 0}, 3)).To(BeTrue())
+		Expect(skyscraper.Allows(s, Cell{1, 0}, 0)).To(BeFalse())
+		Expect(skyscraper.Allows(s, Cell{1, 1}, 3)).To(BeTrue())
+	})
+
+	It("reports the values breaking a clue", func() {
+		sG := patternGrid(4, 2, 2)
+		sG.Clues = []Clue{
+			{Kind: ClueSandwich, Side: SideLeft, Index: 1, Value: 0},
+			{Kind: ClueSkyscraper, Side: SideTop, Index: 0, Value: 3},
+		}
+		Expect(sG.Validate()).To(Succeed())
+
+		sG.Clues[1].Value = 2
+		err := sG.Validate()
+		Expect(err).To(MatchError(ErrConflict))
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Violations).To(Equal([]Violation{
+			{Candidate: Candidate{Cell: Cell{Row: 0, Col: 0}, Value: '1'}, Constraint: 1, Type: "skyscraper"},
+			{Candidate: Candidate{Cell: Cell{Row: 1, Col: 0}, Value: '3'}, Constraint: 1, Type: "skyscraper"},
+			{Candidate: Candidate{Cell: Cell{Row: 2, Col: 0}, Value: '2'}, Constraint: 1, Type: "skyscraper"},
+			{Candidate: Candidate{Cell: Cell{Row: 3, Col: 0}, Value: '4'}, Constraint: 1, Type: "skyscraper"},
+		}))
+	})
+
+	DescribeTable("rejects invalid clues",
+		func(clues []Clue, expected error) {
+			sG, err := New(4, 2, 2)
+			Expect(err).To(BeNil())
+			sG.Clues = clues
+			Expect(sG.Valid()).To(MatchError(expected))
+		},
+		Entry("of an unknown type", []Clue{{Kind: "sum", Side: SideTop, Index: 0, Value: 1}}, ErrInvalidConstraint),
+		Entry("on an unknown side", []Clue{{Kind: ClueXSum, Side: "center", Index: 0, Value: 1}}, ErrInvalidConstraint),
+		Entry("outside of the grid", []Clue{{Kind: ClueXSum, Side: SideTop, Index: 4, Value: 1}}, ErrInvalidConstraint),
+		Entry("out of reach", []Clue{{Kind: ClueSandwich, Side: SideTop, Index: 0, Value: 6}}, ErrInvalidConstraint),
+		Entry("given twice", []Clue{
+			{Kind: ClueSkyscraper, Side: SideTop, Index: 0, Value: 1},
+			{Kind: ClueSkyscraper, Side: SideTop, Index: 0, Value: 3},
+		}, ErrInvalidConstraint),
+	)
+
+	It("writes the clues around the grid", func() {
+		sG := patternGrid(4, 2, 2)
+		sG.Clues = []Clue{
+			{Kind: ClueSandwich, Side: SideLeft, Index: 0, Value: 5},
+			{Kind: ClueXSum, Side: SideLeft, Index: 0, Value: 1},
+			{Kind: ClueXSum, Side: SideTop, Index: 0, Value: 1},
+			{Kind: ClueSkyscraper, Side: SideRight, Index: 1, Value: 2},
+			{Kind: ClueXSum, Side: SideBottom, Index: 3, Value: 10},
+		}
+		sG.Set(1, 1, EMPTY_CELL)
+		Expect(sG.ToStringPrettify()).To(Equal("" +
+			"       1\n" +
+			"    +---+---+---+---+\n" +
+			"1 5 |  1   2|  3   4|\n" +
+			"    +       +       +\n" +
+			"    |  3   .|  1   2| 2\n" +
+			"    +---+---+---+---+\n" +
+			"    |  2   3|  4   1|\n" +
+			"    +       +       +\n" +
+			"    |  4   1|  2   3|\n" +
+			"    +---+---+---+---+\n" +
+			"                  10\n"))
+	})
+
+	It("serializes and copies the clues", func() {
+		sG := patternGrid(4, 2, 2)
+		sG.Clues = []Clue{{Kind: ClueSandwich, Side: SideLeft, Index: 0, Value: 5}}
+		b, err := json.Marshal(sG)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(ContainSubstring(`"clues":[{"type":"sandwich","side":"left","index":0,"value":5}]`))
+		parsed := &SudokuGrid{}
+		Expect(json.Unmarshal(b, parsed)).To(Succeed())
+		Expect(parsed.Clues).To(Equal(sG.Clues))
+
+		clone := sG.Clone()
+		clone.Clues[0].Value = 0
+		Expect(sG.Clues[0].Value).To(Equal(5))
+	})
+})
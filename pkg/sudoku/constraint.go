@@ -0,0 +1,715 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Constraint is an extra rule of a sudoku variant, on top of the rows, columns and subgrids, which the solvers,
+// the validator and the generator all consult. Values are the indexes of the symbols of the grid in order:
+// the value v stands for the number v+1, the digit itself with the default symbols.
+type Constraint interface {
+	// Type is the name of the kind of constraint, it tells the constraints apart in JSON, see RegisterConstraint
+	Type() string
+	// String describes the rule in plain words
+	String() string
+	// Valid returns an error if the constraint does not fit in a size x size grid
+	Valid(size int) error
+	// Related returns the other cells whose values the rule ties to the value of the cell in a size x size grid
+	Related(size int, cell Cell) []Cell
+	// Allows returns true if the cell may hold the value v given the values of the other cells of the state
+	Allows(s *State, cell Cell, v int) bool
+	// Prune removes from the candidates of the state the values ruled out by more than the values of the
+	// related cells, it returns false if a cell is left without candidate
+	Prune(s *State) bool
+}
+
+// State is a grid being solved as seen by a Constraint, the cells are stored in row-major order
+type State struct {
+	Size       int
+	Values     []int    // value of each cell, -1 if the cell is empty
+	Candidates []uint64 // bitset of the values each cell can still hold, nil if unknown
+}
+
+// Value returns the value of the cell, -1 if it is empty
+func (s *State) Value(c Cell) int {
+	return s.Values[c.Row*s.Size+c.Col]
+}
+
+// Remove removes the values of mask from the candidates of the cell, returns false if none is left
+func (s *State) Remove(c Cell, mask uint64) bool {
+	i := c.Row*s.Size + c.Col
+	s.Candidates[i] &^= mask
+	return s.Candidates[i] != 0
+}
+
+// Constraints is a list of constraints represented in JSON by objects holding their Type in a "type" field
+type Constraints []Constraint
+
+var (
+	constraintsMu   sync.RWMutex
+	constraintTypes = map[string]func() Constraint{}
+)
+
+func init() {
+	RegisterConstraint("anti-knight", func() Constraint { return &AntiKnight{} })
+	RegisterConstraint("anti-king", func() Constraint { return &AntiKing{} })
+	RegisterConstraint("non-consecutive", func() Constraint { return &NonConsecutive{} })
+	RegisterConstraint("odd", func() Constraint { return &Odd{} })
+	RegisterConstraint("even", func() Constraint { return &Even{} })
+	RegisterConstraint("thermometer", func() Constraint { return &Thermometer{} })
+	RegisterConstraint("arrow", func() Constraint { return &Arrow{} })
+	RegisterConstraint("kropki", func() Constraint { return &Kropki{} })
+}
+
+// RegisterConstraint makes a kind of Constraint available in JSON under the given type, empty returns an empty
+// constraint of the kind to decode into. It panics if the type is already taken.
+func RegisterConstraint(typ string, empty func() Constraint) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+	if empty == nil {
+		panic("sudoku: RegisterConstraint constructor is nil")
+	}
+	if _, dup := constraintTypes[typ]; dup {
+		panic("sudoku: RegisterConstraint called twice for type " + typ)
+	}
+	constraintTypes[typ] = empty
+}
+
+// NewConstraint returns an empty Constraint of the type registered under the given name
+func NewConstraint(typ string) (Constraint, error) {
+	constraintsMu.RLock()
+	empty, ok := constraintTypes[typ]
+	constraintsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: the type %q is not one of the supported constraints (%s)", ErrInvalidConstraint, typ, strings.Join(ConstraintTypes(), ", "))
+	}
+	return empty(), nil
+}
+
+// ConstraintTypes returns the sorted types of the registered constraints
+func ConstraintTypes() []string {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+	types := make([]string, 0, len(constraintTypes))
+	for typ := range constraintTypes {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (cs Constraints) MarshalJSON() ([]byte, error) {
+	res := []byte{'['}
+	for i, c := range cs {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) < 2 || b[0] != '{' {
+			return nil, fmt.Errorf("%w: the %s constraint is not a JSON object", ErrInvalidConstraint, c.Type())
+		}
+		typ, err := json.Marshal(c.Type())
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			res = append(res, ',')
+		}
+		// the type comes first, followed by the fields of the constraint if any
+		res = append(append(res, `{"type":`...), typ...)
+		if len(b) > 2 {
+			res = append(res, ',')
+		}
+		res = append(res, b[1:]...)
+	}
+	return append(res, ']'), nil
+}
+
+func (cs *Constraints) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	res := make(Constraints, len(raw))
+	for i, r := range raw {
+		var head struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(r, &head); err != nil {
+			return err
+		}
+		c, err := NewConstraint(head.Type)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(r, c); err != nil {
+			return err
+		}
+		res[i] = c
+	}
+	*cs = res
+	return nil
+}
+
+// WithConstraints generates grids following the given constraints on top of the rules of the variant
+func WithConstraints(constraints ...Constraint) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.constraints = append(o.constraints, constraints...)
+	}
+}
+
+// clone returns a copy of the list, the constraints themselves are never modified and are shared
+func (cs Constraints) clone() Constraints {
+	if cs == nil {
+		return nil
+	}
+	res := make(Constraints, len(cs))
+	copy(res, cs)
+	return res
+}
+
+var (
+	knightMoves = []Cell{{-2, -1}, {-2, 1}, {-1, -2}, {-1, 2}, {1, -2}, {1, 2}, {2, -1}, {2, 1}}
+	kingMoves   = []Cell{{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, -1}, {1, 0}, {1, 1}}
+	orthogonal  = []Cell{{-1, 0}, {0, -1}, {0, 1}, {1, 0}}
+)
+
+// moved returns the cells of the size x size grid reached from the cell with the given moves
+func moved(size int, cell Cell, moves []Cell) []Cell {
+	var res []Cell
+	for _, m := range moves {
+		c := Cell{Row: cell.Row + m.Row, Col: cell.Col + m.Col}
+		if c.Row >= 0 && c.Row < size && c.Col >= 0 && c.Col < size {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// movedAllow returns true if ok holds for the value of every filled cell reached from the cell with the given moves
+func movedAllow(s *State, cell Cell, moves []Cell, ok func(w int) bool) bool {
+	for _, m := range moves {
+		c := Cell{Row: cell.Row + m.Row, Col: cell.Col + m.Col}
+		if c.Row < 0 || c.Row >= s.Size || c.Col < 0 || c.Col >= s.Size {
+			continue
+		}
+		if w := s.Value(c); w != -1 && !ok(w) {
+			return false
+		}
+	}
+	return true
+}
+
+// AntiKnight forbids the same value in two cells a chess knight's move apart
+type AntiKnight struct{}
+
+func (AntiKnight) Type() string                       { return "anti-knight" }
+func (AntiKnight) String() string                     { return "cells a knight's move apart hold different values" }
+func (AntiKnight) Valid(size int) error               { return nil }
+func (AntiKnight) Related(size int, cell Cell) []Cell { return moved(size, cell, knightMoves) }
+func (AntiKnight) Prune(s *State) bool                { return true }
+
+func (AntiKnight) Allows(s *State, cell Cell, v int) bool {
+	return movedAllow(s, cell, knightMoves, func(w int) bool { return w != v })
+}
+
+// AntiKing forbids the same value in two cells a chess king's move apart, including diagonally
+type AntiKing struct{}
+
+func (AntiKing) Type() string                       { return "anti-king" }
+func (AntiKing) String() string                     { return "cells a king's move apart hold different values" }
+func (AntiKing) Valid(size int) error               { return nil }
+func (AntiKing) Related(size int, cell Cell) []Cell { return moved(size, cell, kingMoves) }
+func (AntiKing) Prune(s *State) bool                { return true }
+
+func (AntiKing) Allows(s *State, cell Cell, v int) bool {
+	return movedAllow(s, cell, kingMoves, func(w int) bool { return w != v })
+}
+
+// NonConsecutive forbids consecutive values in two orthogonally adjacent cells
+type NonConsecutive struct{}
+
+func (NonConsecutive) Type() string { return "non-consecutive" }
+func (NonConsecutive) String() string {
+	return "orthogonally adjacent cells hold no consecutive values"
+}
+func (NonConsecutive) Valid(size int) error               { return nil }
+func (NonConsecutive) Related(size int, cell Cell) []Cell { return moved(size, cell, orthogonal) }
+func (NonConsecutive) Prune(s *State) bool                { return true }
+
+func (NonConsecutive) Allows(s *State, cell Cell, v int) bool {
+	return movedAllow(s, cell, orthogonal, func(w int) bool { return w != v-1 && w != v+1 })
+}
+
+// validCells returns an error if a cell is outside of the size x size grid or is given twice
+func validCells(size int, cells []Cell) error {
+	seen := map[Cell]bool{}
+	for _, c := range cells {
+		if c.Row < 0 || c.Row >= size || c.Col < 0 || c.Col >= size {
+			return &OutOfBoundsError{Row: c.Row, Col: c.Col}
+		}
+		if seen[c] {
+			return fmt.Errorf("%w: r%dc%d is given twice", ErrInvalidConstraint, c.Row+1, c.Col+1)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// adjacent returns true if the two cells touch, diagonally if diagonal is true
+func adjacent(a, b Cell, diagonal bool) bool {
+	dr, dc := abs(a.Row-b.Row), abs(a.Col-b.Col)
+	if diagonal {
+		return dr <= 1 && dc <= 1 && dr+dc > 0
+	}
+	return dr+dc == 1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// indexOf returns the index of the cell in cells, -1 if it is not there
+func indexOf(cells []Cell, cell Cell) int {
+	for i, c := range cells {
+		if c == cell {
+			return i
+		}
+	}
+	return -1
+}
+
+// others returns the cells but the given one
+func others(cells []Cell, cell Cell) []Cell {
+	res := make([]Cell, 0, len(cells))
+	for _, c := range cells {
+		if c != cell {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// cellNames returns the names of the cells such as "r1c2, r1c3", indexes start from 1
+func cellNames(cells []Cell) string {
+	names := make([]string, len(cells))
+	for i, c := range cells {
+		names[i] = fmt.Sprintf("r%dc%d", c.Row+1, c.Col+1)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Odd restricts the cells to odd values
+type Odd struct {
+	Cells []Cell `json:"cells"`
+}
+
+func (Odd) Type() string                       { return "odd" }
+func (o Odd) String() string                   { return cellNames(o.Cells) + " hold odd values" }
+func (Odd) Related(size int, cell Cell) []Cell { return nil }
+func (Odd) Prune(s *State) bool                { return true }
+func (o Odd) Allows(s *State, cell Cell, v int) bool {
+	return indexOf(o.Cells, cell) == -1 || (v+1)%2 == 1
+}
+
+func (o Odd) Valid(size int) error {
+	if len(o.Cells) == 0 {
+		return fmt.Errorf("%w: odd cells without cells", ErrInvalidConstraint)
+	}
+	return validCells(size, o.Cells)
+}
+
+// Even restricts the cells to even values
+type Even struct {
+	Cells []Cell `json:"cells"`
+}
+
+func (Even) Type() string                       { return "even" }
+func (e Even) String() string                   { return cellNames(e.Cells) + " hold even values" }
+func (Even) Related(size int, cell Cell) []Cell { return nil }
+func (Even) Prune(s *State) bool                { return true }
+func (e Even) Allows(s *State, cell Cell, v int) bool {
+	return indexOf(e.Cells, cell) == -1 || (v+1)%2 == 0
+}
+
+func (e Even) Valid(size int) error {
+	if len(e.Cells) == 0 {
+		return fmt.Errorf("%w: even cells without cells", ErrInvalidConstraint)
+	}
+	return validCells(size, e.Cells)
+}
+
+// Thermometer makes the values strictly increase along its cells, starting from the bulb
+type Thermometer struct {
+	Cells []Cell `json:"cells"` // the bulb first, each cell touching the previous one, diagonally or not
+}
+
+func (Thermometer) Type() string { return "thermometer" }
+
+func (t Thermometer) String() string {
+	return "the values increase along " + cellNames(t.Cells)
+}
+
+func (t Thermometer) Valid(size int) error {
+	if len(t.Cells) < 2 || len(t.Cells) > size {
+		return fmt.Errorf("%w: a thermometer has between 2 and %d cells, not %d", ErrInvalidConstraint, size, len(t.Cells))
+	}
+	if err := validCells(size, t.Cells); err != nil {
+		return err
+	}
+	for i := 1; i < len(t.Cells); i++ {
+		if !adjacent(t.Cells[i-1], t.Cells[i], true) {
+			return fmt.Errorf("%w: the thermometer cells %s do not touch", ErrInvalidConstraint, cellNames(t.Cells[i-1:i+1]))
+		}
+	}
+	return nil
+}
+
+func (t Thermometer) Related(size int, cell Cell) []Cell {
+	if indexOf(t.Cells, cell) == -1 {
+		return nil
+	}
+	return others(t.Cells, cell)
+}
+
+// Allows checks the value against the filled cells of the thermometer, leaving room for the cells in between
+func (t Thermometer) Allows(s *State, cell Cell, v int) bool {
+	i := indexOf(t.Cells, cell)
+	if i == -1 {
+		return true
+	}
+	if v < i || v > s.Size-len(t.Cells)+i {
+		return false
+	}
+	for j, c := range t.Cells {
+		w := s.Value(c)
+		if j == i || w == -1 {
+			continue
+		}
+		if (j < i && w+i-j > v) || (j > i && v+j-i > w) {
+			return false
+		}
+	}
+	return true
+}
+
+// Prune keeps the candidates of each cell above the smallest candidate of the previous cell and below the
+// largest candidate of the next one
+func (t Thermometer) Prune(s *State) bool {
+	if s.Candidates == nil {
+		return true
+	}
+	low := -1
+	for _, c := range t.Cells {
+		if !s.Remove(c, uint64(1)<<uint(low+1)-1) {
+			return false
+		}
+		low = bits.TrailingZeros64(s.Candidates[c.Row*s.Size+c.Col])
+	}
+	high := s.Size
+	for i := len(t.Cells) - 1; i >= 0; i-- {
+		c := t.Cells[i]
+		if !s.Remove(c, ^(uint64(1)<<uint(high) - 1)) {
+			return false
+		}
+		high = 63 - bits.LeadingZeros64(s.Candidates[c.Row*s.Size+c.Col])
+	}
+	return true
+}
+
+// Arrow makes the value of the circle the sum of the values along the arrow, which may repeat
+type Arrow struct {
+	Circle Cell   `json:"circle"`
+	Cells  []Cell `json:"cells"` // cells of the arrow from the circle, each cell touching the previous one
+}
+
+func (Arrow) Type() string { return "arrow" }
+
+func (a Arrow) String() string {
+	return "the values of " + cellNames(a.Cells) + " add up to the value of " + cellNames([]Cell{a.Circle})
+}
+
+func (a Arrow) Valid(size int) error {
+	if len(a.Cells) == 0 {
+		return fmt.Errorf("%w: an arrow has at least one cell", ErrInvalidConstraint)
+	}
+	if err := validCells(size, append([]Cell{a.Circle}, a.Cells...)); err != nil {
+		return err
+	}
+	if len(a.Cells) > size {
+		return fmt.Errorf("%w: the %d cells of the arrow add up to more than %d", ErrInvalidConstraint, len(a.Cells), size)
+	}
+	prev := a.Circle
+	for _, c := range a.Cells {
+		if !adjacent(prev, c, true) {
+			return fmt.Errorf("%w: the arrow cells %s do not touch", ErrInvalidConstraint, cellNames([]Cell{prev, c}))
+		}
+		prev = c
+	}
+	return nil
+}
+
+func (a Arrow) Related(size int, cell Cell) []Cell {
+	if cell != a.Circle && indexOf(a.Cells, cell) == -1 {
+		return nil
+	}
+	return others(append([]Cell{a.Circle}, a.Cells...), cell)
+}
+
+// Allows checks the circle can still be reached given the filled cells, the empty cells of the arrow holding
+// at least 1 and at most Size each
+func (a Arrow) Allows(s *State, cell Cell, v int) bool {
+	if cell != a.Circle && indexOf(a.Cells, cell) == -1 {
+		return true
+	}
+	value := func(c Cell) int {
+		if c == cell {
+			return v
+		}
+		return s.Value(c)
+	}
+	sum, empty := 0, 0
+	for _, c := range a.Cells {
+		if w := value(c); w != -1 {
+			sum += w + 1
+		} else {
+			empty++
+		}
+	}
+	target := value(a.Circle)
+	if target == -1 {
+		return sum+empty <= s.Size
+	}
+	return sum+empty <= target+1 && target+1 <= sum+empty*s.Size
+}
+
+// Prune keeps the candidates of the circle between the smallest and the largest sums of the candidates of the
+// arrow, and those of each cell of the arrow within what the circle leaves to it
+func (a Arrow) Prune(s *State) bool {
+	if s.Candidates == nil {
+		return true
+	}
+	candidates := func(c Cell) uint64 { return s.Candidates[c.Row*s.Size+c.Col] }
+	low := func(c Cell) int { return bits.TrailingZeros64(candidates(c)) + 1 }
+	high := func(c Cell) int { return 64 - bits.LeadingZeros64(candidates(c)) }
+	// between keeps the numbers from min to max in the candidates of the cell
+	between := func(c Cell, min, max int) bool {
+		var keep uint64
+		if max >= min && max > 0 {
+			if min < 1 {
+				min = 1
+			}
+			if max > s.Size {
+				max = s.Size
+			}
+			keep = (uint64(1)<<uint(max) - 1) &^ (uint64(1)<<uint(min-1) - 1)
+		}
+		return s.Remove(c, ^keep)
+	}
+
+	sumLow, sumHigh := 0, 0
+	for _, c := range a.Cells {
+		sumLow, sumHigh = sumLow+low(c), sumHigh+high(c)
+	}
+	if !between(a.Circle, sumLow, sumHigh) {
+		return false
+	}
+	for _, c := range a.Cells {
+		othersLow, othersHigh := sumLow-low(c), sumHigh-high(c)
+		if !between(c, low(a.Circle)-othersHigh, high(a.Circle)-othersLow) {
+			return false
+		}
+	}
+	return true
+}
+
+// Kropki dot colors: a white dot joins consecutive values, a black dot values one of which is twice the other
+const (
+	KropkiWhite = "white"
+	KropkiBlack = "black"
+)
+
+// Kropki is a dot between two orthogonally adjacent cells relating their values, see KropkiWhite and KropkiBlack
+type Kropki struct {
+	Color string  `json:"color"`
+	Cells [2]Cell `json:"cells"`
+}
+
+func (Kropki) Type() string { return "kropki" }
+
+func (k Kropki) String() string {
+	if k.Color == KropkiBlack {
+		return "one of the values of " + cellNames(k.Cells[:]) + " is twice the other"
+	}
+	return cellNames(k.Cells[:]) + " hold consecutive values"
+}
+
+func (k Kropki) Valid(size int) error {
+	if k.Color != KropkiWhite && k.Color != KropkiBlack {
+		return fmt.Errorf("%w: the color of a kropki dot is %s or %s, not %q", ErrInvalidConstraint, KropkiWhite, KropkiBlack, k.Color)
+	}
+	if err := validCells(size, k.Cells[:]); err != nil {
+		return err
+	}
+	if !adjacent(k.Cells[0], k.Cells[1], false) {
+		return fmt.Errorf("%w: the kropki cells %s are not orthogonally adjacent", ErrInvalidConstraint, cellNames(k.Cells[:]))
+	}
+	return nil
+}
+
+func (k Kropki) Related(size int, cell Cell) []Cell {
+	if indexOf(k.Cells[:], cell) == -1 {
+		return nil
+	}
+	return others(k.Cells[:], cell)
+}
+
+func (k Kropki) Prune(s *State) bool { return true }
+
+// Allows checks the value against the other cell of the dot, or that some value may go with it if it is empty
+func (k Kropki) Allows(s *State, cell Cell, v int) bool {
+	i := indexOf(k.Cells[:], cell)
+	if i == -1 {
+		return true
+	}
+	a := v + 1
+	w := s.Value(k.Cells[1-i])
+	if w == -1 {
+		// a white dot always has a consecutive value in the grid, a black one needs half or twice the value
+		return k.Color == KropkiWhite || a%2 == 0 || 2*a <= s.Size
+	}
+	b := w + 1
+	if k.Color == KropkiBlack {
+		return a == 2*b || b == 2*a
+	}
+	return a == b+1 || b == a+1
+}
+
+// constrain removes from the candidates of the cells related to the cell by a constraint the values the
+// constraint does not allow anymore, then lets the constraints of the cell prune the candidates. The candidates are
+// removed with eliminate, it returns false as soon as eliminate does or a constraint leaves a cell without candidate.
+// The related cells filled in the meantime are checked too, eliminate is then expected to fail on their value.
+func (l *layout) constrain(values []int, candidates []uint64, cell int, eliminate func(cell, v int) bool) bool {
+	s := &State{Size: l.size, Values: values}
+	for _, k := range l.cellConstraints[cell] {
+		for _, other := range l.related[k][cell] {
+			c := Cell{Row: other / l.size, Col: other % l.size}
+			for m := candidates[other]; m != 0; m &= m - 1 {
+				v := bits.TrailingZeros64(m)
+				if !l.constraints[k].Allows(s, c, v) && !eliminate(other, v) {
+					return false
+				}
+			}
+		}
+	}
+	for _, k := range l.cellConstraints[cell] {
+		if !l.prune(k, values, candidates, eliminate) {
+			return false
+		}
+	}
+	return true
+}
+
+// prune lets the constraint k prune a copy of the candidates and removes with eliminate the values it dropped
+func (l *layout) prune(k int, values []int, candidates []uint64, eliminate func(cell, v int) bool) bool {
+	s := &State{Size: l.size, Values: values, Candidates: make([]uint64, len(candidates))}
+	copy(s.Candidates, candidates)
+	if !l.constraints[k].Prune(s) {
+		return false
+	}
+	for cell := range candidates {
+		for removed := candidates[cell] &^ s.Candidates[cell]; removed != 0; removed &= removed - 1 {
+			if !eliminate(cell, bits.TrailingZeros64(removed)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// restrictAll removes the values not allowed by the constraints from the candidates of every cell, the values of
+// the filled cells included, then lets every constraint prune the candidates, see constrain
+func (l *layout) restrictAll(values []int, candidates []uint64, eliminate func(cell, v int) bool) bool {
+	s := &State{Size: l.size, Values: values}
+	for cell := range candidates {
+		c := Cell{Row: cell / l.size, Col: cell % l.size}
+		for m := candidates[cell]; m != 0; m &= m - 1 {
+			v := bits.TrailingZeros64(m)
+			for _, k := range l.constraints {
+				if !k.Allows(s, c, v) {
+					if !eliminate(cell, v) {
+						return false
+					}
+					break
+				}
+			}
+		}
+	}
+	for k := range l.constraints {
+		if !l.prune(k, values, candidates, eliminate) {
+			return false
+		}
+	}
+	return true
+}
+
+// validConstraints returns an error if a constraint of the grid is missing or does not fit in it
+func (sG *SudokuGrid) validConstraints() error {
+	for i, c := range sG.Constraints {
+		if c == nil {
+			return fmt.Errorf("%w: constraint %d is missing", ErrInvalidConstraint, i+1)
+		}
+		if err := c.Valid(sG.Size); err != nil {
+			return fmt.Errorf("constraint %d (%s): %w", i+1, c.Type(), err)
+		}
+	}
+	return nil
+}
+
+// state returns the values of the grid as seen by the constraints along with the value of each symbol,
+// the cells holding no symbol of the grid are empty
+func (sG *SudokuGrid) state() (*State, map[rune]int) {
+	index := map[rune]int{}
+	for v, symbol := range sG.symbols() {
+		index[symbol] = v
+	}
+	s := &State{Size: sG.Size, Values: make([]int, sG.Size*sG.Size)}
+	for i := range sG.Grid {
+		for j, symbol := range sG.Grid[i] {
+			v, ok := index[symbol]
+			if !ok {
+				v = -1
+			}
+			s.Values[i*sG.Size+j] = v
+		}
+	}
+	return s, index
+}
+
+// constraintsAllow returns true if all the constraints, the inequalities and the clues of the grid allow val in the cell with coordinates (x, y)
+// given the values of the other cells
+func (sG *SudokuGrid) constraintsAllow(x, y int, val rune) bool {
+	rules := sG.rules()
+	if len(rules) == 0 {
+		return true
+	}
+	s, index := sG.state()
+	v, ok := index[val]
+	if !ok {
+		return true
+	}
+	for _, c := range rules {
+		if !c.Allows(s, Cell{Row: x, Col: y}, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// constrainedGrid returns an empty 4x4 grid whose first row starts with a thermometer, the bulb holding 1 and its
+// end a value twice the one below it, with an arrow from r3c1 through r4c2 and r4c3 and an even r4c4
+func constrainedGrid() *SudokuGrid {
+	sG, _ := New(4, 2, 2)
+	sG.Set(0, 0, '1')
+	sG.Constraints = Constraints{
+		Thermometer{Cells: []Cell{{0, 0}, {0, 1}, {0, 2}}},
+		Kropki{Color: KropkiBlack, Cells: [2]Cell{{0, 2}, {1, 2}}},
+		Arrow{Circle: Cell{2, 0}, Cells: []Cell{{3, 1}, {3, 2}}},
+		Even{Cells: []Cell{{3, 3}}},
+	}
+	return sG
+}
+
+// referenceCount counts the solutions of the SudokuGrid with the plain backtracking of SudokuGrid.solve
+func referenceCount(sG *SudokuGrid) int {
+	count := 0
+	work := sG.Clone()
+	work.solve(work.missingCells(), func() bool {
+		count++
+		return false
+	})
+	return count
+}
+
+var _ = Describe("Constraint", func() {
+	DescribeTable("allows a value given the values of the other cells",
+		func(c Constraint, cell Cell, v int, expected bool) {
+			sG, err := New(4, 2, 2)
+			Expect(err).To(BeNil())
+			sG.Set(1, 1, '2')
+			s, _ := sG.state()
+			Expect(c.Allows(s, cell, v)).To(Equal(expected))
+		},
+		Entry("anti-knight, a knight's move away", AntiKnight{}, Cell{3, 2}, 1, false),
+		Entry("anti-knight, another value", AntiKnight{}, Cell{3, 2}, 2, true),
+		Entry("anti-king, diagonally", AntiKing{}, Cell{0, 0}, 1, false),
+		Entry("non-consecutive, next to it", NonConsecutive{}, Cell{1, 2}, 2, false),
+		Entry("non-consecutive, diagonally", NonConsecutive{}, Cell{0, 0}, 2, true),
+		Entry("odd", Odd{Cells: []Cell{{0, 0}}}, Cell{0, 0}, 1, false),
+		Entry("odd, another cell", Odd{Cells: []Cell{{0, 0}}}, Cell{0, 1}, 1, true),
+		Entry("even", Even{Cells: []Cell{{0, 0}}}, Cell{0, 0}, 1, true),
+		Entry("thermometer, above the bulb", Thermometer{Cells: []Cell{{1, 1}, {1, 2}}}, Cell{1, 2}, 2, true),
+		Entry("thermometer, leaving no room", Thermometer{Cells: []Cell{{0, 0}, {1, 1}, {1, 2}}}, Cell{0, 0}, 1, false),
+		Entry("arrow, a sum within reach", Arrow{Circle: Cell{0, 0}, Cells: []Cell{{1, 1}, {2, 2}}}, Cell{0, 0}, 3, true),
+		Entry("arrow, a sum too small", Arrow{Circle: Cell{0, 0}, Cells: []Cell{{1, 1}, {2, 2}}}, Cell{0, 0}, 1, false),
+		Entry("white kropki", Kropki{Color: KropkiWhite, Cells: [2]Cell{{1, 1}, {1, 2}}}, Cell{1, 2}, 0, true),
+		Entry("black kropki", Kropki{Color: KropkiBlack, Cells: [2]Cell{{1, 1}, {1, 2}}}, Cell{1, 2}, 2, false),
+		Entry("black kropki, without a half nor a double", Kropki{Color: KropkiBlack, Cells: [2]Cell{{0, 0}, {0, 1}}}, Cell{0, 0}, 2, false),
+	)
+
+	It("prunes the candidates of thermometers and arrows", func() {
+		s := &State{Size: 4, Values: make([]int, 16), Candidates: make([]uint64, 16)}
+		for i := range s.Values {
+			s.Values[i], s.Candidates[i] = -1, 0xf
+		}
+		Expect(Thermometer{Cells: []Cell{{0, 0}, {0, 1}, {0, 2}}}.Prune(s)).To(BeTrue())
+		Expect(s.Candidates[:3]).To(Equal([]uint64{0x3, 0x6, 0xc}))
+
+		Expect(Arrow{Circle: Cell{1, 0}, Cells: []Cell{{2, 0}, {2, 1}}}.Prune(s)).To(BeTrue())
+		Expect(s.Candidates[4]).To(Equal(uint64(0xe)))
+		Expect(s.Candidates[8]).To(Equal(uint64(0x7)))
+	})
+
+	It("takes the constraints into account when solving", func() {
+		sG := constrainedGrid()
+		expected := referenceCount(sG)
+		Expect(expected).To(BeNumerically(">", 0))
+		unconstrained := sG.Clone()
+		unconstrained.Constraints = nil
+		Expect(expected).To(BeNumerically("<", referenceCount(unconstrained)))
+
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			solutions, _, err := sG.FindSolutionsWith(context.Background(), s, 0)
+			Expect(err).To(BeNil())
+			Expect(solutions).To(HaveLen(expected))
+			for _, solution := range solutions {
+				Expect(solution.Validate()).To(Succeed())
+			}
+		}
+
+		solution := sG.Clone()
+		_, err := solution.SolveLogically()
+		Expect(err).To(BeNil())
+		Expect(solution.Validate()).To(Succeed())
+	})
+
+	It("reports a puzzle whose clues break a constraint as unsolvable", func() {
+		sG := constrainedGrid()
+		sG.Set(3, 3, '1')
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			_, err = sG.Clone().SolveWith(context.Background(), s)
+			Expect(err).To(MatchError(ErrNoSolution))
+		}
+		_, err := sG.Clone().SolveLogically()
+		Expect(err).To(MatchError(ErrNoSolution))
+	})
+
+	DescribeTable("generates grids following the constraints",
+		func(size, partitionWidth, partitionHeight int, constraints ...Constraint) {
+			sG, err := GenerateSudokuGrid(size, partitionWidth, partitionHeight, WithConstraints(constraints...))
+			Expect(err).To(BeNil())
+			Expect(sG.Constraints).To(HaveLen(len(constraints)))
+			Expect(isSolved(sG)).To(BeTrue())
+			Expect(sG.Validate()).To(Succeed())
+
+			Expect(sG.SetGridToLevel("medium", WithUniqueSolution())).To(Succeed())
+			Expect(sG.IsUnique()).To(BeTrue())
+		},
+		Entry("anti-knight", 9, 3, 3, AntiKnight{}),
+		Entry("anti-king", 9, 3, 3, AntiKing{}),
+		Entry("non-consecutive", 9, 3, 3, NonConsecutive{}),
+		Entry("anti-knight and anti-king", 9, 3, 3, AntiKnight{}, AntiKing{}),
+	)
+
+	It("restricts the candidates to the values the constraints allow", func() {
+		sG := constrainedGrid()
+		candidates := sG.Candidates()
+		Expect(candidates[0][1]).To(Equal([]rune("23")))
+		Expect(candidates[3][3]).To(Equal([]rune("24")))
+	})
+
+	It("reports the values breaking a constraint", func() {
+		sG := constrainedGrid()
+		Expect(sG.Validate()).To(Succeed())
+
+		// 2 leaves no room for r1c2 between the bulb and r1c3
+		sG.Set(0, 2, '2')
+		err := sG.Validate()
+		Expect(err).To(MatchError(ErrConflict))
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Violations).To(Equal([]Violation{
+			{Candidate: Candidate{Cell: Cell{Row: 0, Col: 0}, Value: '1'}, Constraint: 0, Type: "thermometer"},
+			{Candidate: Candidate{Cell: Cell{Row: 0, Col: 2}, Value: '2'}, Constraint: 0, Type: "thermometer"},
+		}))
+		Expect(err.Error()).To(ContainSubstring("2 in r1c3 breaks the thermometer constraint 1"))
+
+		hint, err := sG.Hint()
+		Expect(err).To(BeNil())
+		Expect(hint.Conflicts).To(Equal([]Cell{{Row: 0, Col: 0}, {Row: 0, Col: 2}}))
+	})
+
+	DescribeTable("rejects invalid constraints",
+		func(c Constraint, expected error) {
+			sG := constrainedGrid()
+			sG.Constraints = append(sG.Constraints, c)
+			Expect(sG.Valid()).To(MatchError(expected))
+		},
+		Entry("missing", nil, ErrInvalidConstraint),
+		Entry("odd cells outside of the grid", Odd{Cells: []Cell{{4, 0}}}, ErrOutOfBounds),
+		Entry("a thermometer of one cell", Thermometer{Cells: []Cell{{1, 1}}}, ErrInvalidConstraint),
+		Entry("a thermometer whose cells do not touch", Thermometer{Cells: []Cell{{1, 1}, {1, 3}}}, ErrInvalidConstraint),
+		Entry("an arrow through its circle", Arrow{Circle: Cell{1, 1}, Cells: []Cell{{1, 2}, {1, 1}}}, ErrInvalidConstraint),
+		Entry("a kropki dot of an unknown color", Kropki{Color: "red", Cells: [2]Cell{{1, 1}, {1, 2}}}, ErrInvalidConstraint),
+		Entry("a kropki dot between diagonal cells", Kropki{Color: KropkiWhite, Cells: [2]Cell{{1, 1}, {2, 2}}}, ErrInvalidConstraint),
+	)
+
+	It("serializes the constraints with their type", func() {
+		sG := constrainedGrid()
+		sG.Constraints = append(sG.Constraints, AntiKnight{})
+		b, err := json.Marshal(sG)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(ContainSubstring(`"constraints":[{"type":"thermometer","cells":[{"row":0,"col":0},`))
+		Expect(string(b)).To(ContainSubstring(`{"type":"kropki","color":"black","cells":`))
+		Expect(string(b)).To(ContainSubstring(`{"type":"anti-knight"}]`))
+
+		parsed := &SudokuGrid{}
+		Expect(json.Unmarshal(b, parsed)).To(Succeed())
+		Expect(parsed.Constraints).To(HaveLen(5))
+		for i, c := range parsed.Constraints {
+			Expect(c.String()).To(Equal(sG.Constraints[i].String()))
+		}
+		Expect(parsed.CountSolutions(0)).To(Equal(referenceCount(sG)))
+
+		err = json.Unmarshal([]byte(`{"size":4,"partitionWidth":2,"partitionHeight":2,"constraints":[{"type":"sandwich"}]}`), parsed)
+		Expect(err).To(MatchError(ErrInvalidConstraint))
+	})
+})
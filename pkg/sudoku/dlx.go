@@ -0,0 +1,342 @@
+package sudoku
+
+import (
+	"context"
+	"math/bits"
+)
+
+// DLXSolver solves puzzles with Knuth's Algorithm X implemented with Dancing Links.
+// The SudokuGrid is encoded as an exact-cover matrix: each row places a value in a cell,
+// and covers the column of the cell plus one column per (unit, value) pair.
+// The cages of a Killer Sudoku are not part of the matrix: once a value is placed in a cage, the rows of the
+// other cells of the cage which cannot add up to its sum anymore are hidden until the value is removed.
+// The constraints of the grid are enforced the same way for the cells they relate.
+type DLXSolver struct {
+	maxNodes int // number of search nodes after which the search gives up, unlimited if 0
+}
+
+// Search implements Solver
+func (s DLXSolver) Search(ctx context.Context, sG *SudokuGrid, found func() bool) (bool, Stats) {
+	stopped, stats, _ := sG.search(ctx, s, found)
+	return stopped, stats
+}
+
+// searchLayout implements layoutSearcher
+func (s DLXSolver) searchLayout(ctx context.Context, l *layout, values []int, found func([]int) bool) (bool, Stats) {
+	var stats Stats
+	d := newDLX(l, values)
+	stopped := d.search(ctx, &stats, s.maxNodes, func() bool {
+		// every cell is placed once the columns are all covered
+		return found(d.state.Values)
+	})
+	return stopped, stats
+}
+
+// dlxRow is the placement of a value in a cell represented by a row of the matrix
+type dlxRow struct {
+	cell, value int
+}
+
+// dlx is a toroidal doubly linked sparse matrix stored in parallel slices.
+// Node 0 is the root, nodes 1..columns are the column headers, the remaining nodes are the matrix ones.
+type dlx struct {
+	*layout
+	left, right, up, down []int
+	column                []int // header of the column of each node
+	row                   []int // index in rows of the row of each node
+	count                 []int // number of nodes left in each column, indexed by header
+	rows                  []dlxRow
+	solution              []int    // indexes in rows of the rows currently selected
+	cageLeft              []int    // sum each cage has left to reach
+	cageEmpty             []int    // number of empty cells of each cage
+	cageUsed              []uint64 // values placed in each cage
+	hidden                []int    // a node of each row hidden because of a cage or a constraint, in the order they were hidden
+	state                 *State   // values placed so far, as seen by the constraints
+}
+
+// newDLX builds the matrix of the layout, the rows of the filled cells being limited to their given value
+func newDLX(l *layout, values []int) *dlx {
+	cells := l.cells
+	columns := cells + len(l.units)*l.size
+
+	d := &dlx{
+		layout:    l,
+		count:     make([]int, columns+1),
+		cageLeft:  make([]int, len(l.cages)),
+		cageEmpty: make([]int, len(l.cages)),
+		cageUsed:  make([]uint64, len(l.cages)),
+		state:     &State{Size: l.size, Values: make([]int, cells)},
+	}
+	for cell := range d.state.Values {
+		d.state.Values[cell] = -1
+	}
+	for c, cage := range l.cages {
+		d.cageLeft[c], d.cageEmpty[c] = l.cageSums[c], len(cage)
+	}
+	for i := 0; i <= columns; i++ {
+		d.left = append(d.left, i-1)
+		d.right = append(d.right, i+1)
+		d.up = append(d.up, i)
+		d.down = append(d.down, i)
+		d.column = append(d.column, i)
+		d.row = append(d.row, -1)
+	}
+	d.left[0] = columns
+	d.right[columns] = 0
+
+	for cell, value := range values {
+		if value != -1 {
+			d.addRow(cell, value)
+			continue
+		}
+		for v := 0; v < l.size; v++ {
+			if c := l.cellCage[cell]; (c == -1 || d.cageAllows(c, v)) && d.allows(cell, v) {
+				d.addRow(cell, v)
+			}
+		}
+	}
+	return d
+}
+
+// addRow appends the row placing v in the cell to the matrix
+func (d *dlx) addRow(cell, v int) {
+	headers := []int{1 + cell}
+	for _, u := range d.cellUnits[cell] {
+		headers = append(headers, 1+d.cells+u*d.size+v)
+	}
+
+	r := len(d.rows)
+	d.rows = append(d.rows, dlxRow{cell: cell, value: v})
+
+	first := len(d.left)
+	for i, header := range headers {
+		node := first + i
+		d.left = append(d.left, first+(i+len(headers)-1)%len(headers))
+		d.right = append(d.right, first+(i+1)%len(headers))
+		d.up = append(d.up, d.up[header])
+		d.down = append(d.down, header)
+		d.column = append(d.column, header)
+		d.row = append(d.row, r)
+
+		d.down[d.up[header]] = node
+		d.up[header] = node
+		d.count[header]++
+	}
+}
+
+// cover removes the column c from the header list and all the rows intersecting it from the other columns
+func (d *dlx) cover(c int) {
+	d.right[d.left[c]] = d.right[c]
+	d.left[d.right[c]] = d.left[c]
+	for i := d.down[c]; i != c; i = d.down[i] {
+		for j := d.right[i]; j != i; j = d.right[j] {
+			d.down[d.up[j]] = d.down[j]
+			d.up[d.down[j]] = d.up[j]
+			d.count[d.column[j]]--
+		}
+	}
+}
+
+// uncover undoes cover(c), the links are restored in the reverse order
+func (d *dlx) uncover(c int) {
+	for i := d.up[c]; i != c; i = d.up[i] {
+		for j := d.left[i]; j != i; j = d.left[j] {
+			d.count[d.column[j]]++
+			d.down[d.up[j]] = j
+			d.up[d.down[j]] = j
+		}
+	}
+	d.right[d.left[c]] = c
+	d.left[d.right[c]] = c
+}
+
+// search runs Algorithm X, calling found every time the remaining columns are all covered.
+// The search stops as soon as found returns true, in which case search returns true. It gives up once ctx is done
+// or maxNodes nodes are visited, if maxNodes is positive.
+func (d *dlx) search(ctx context.Context, stats *Stats, maxNodes int, found func() bool) bool {
+	if done(ctx) || (maxNodes > 0 && stats.Nodes >= maxNodes) {
+		return false
+	}
+	stats.Nodes++
+	if d.right[0] == 0 {
+		return found()
+	}
+
+	// choose the column with the fewest rows left
+	c := d.right[0]
+	for j := d.right[c]; j != 0; j = d.right[j] {
+		if d.count[j] < d.count[c] {
+			c = j
+		}
+	}
+	if d.count[c] == 0 {
+		return false
+	}
+
+	d.cover(c)
+	for r := d.down[c]; r != c; r = d.down[r] {
+		row := d.rows[d.row[r]]
+		if !d.place(row) {
+			continue
+		}
+		d.solution = append(d.solution, d.row[r])
+		for j := d.right[r]; j != r; j = d.right[j] {
+			d.cover(d.column[j])
+		}
+		hidden := len(d.hidden)
+		d.hideCageRows(row.cell)
+		d.hideConstrainedRows(row.cell)
+
+		if d.search(ctx, stats, maxNodes, found) {
+			return true
+		}
+		if done(ctx) || (maxNodes > 0 && stats.Nodes >= maxNodes) {
+			return false
+		}
+		stats.Backtracks++
+
+		d.unhideRows(hidden)
+		for j := d.left[r]; j != r; j = d.left[j] {
+			d.uncover(d.column[j])
+		}
+		d.solution = d.solution[:len(d.solution)-1]
+		d.unplace(row)
+	}
+	d.uncover(c)
+	return false
+}
+
+// place records the row in the cage of its cell and in the state of the constraints, returns false without
+// recording it if a constraint does not allow the value, the value is already in the cage or the other empty cells
+// could not add up to the rest of its sum anymore
+func (d *dlx) place(row dlxRow) bool {
+	if !d.allows(row.cell, row.value) {
+		return false
+	}
+	c := d.cellCage[row.cell]
+	if c != -1 {
+		if !d.cageAllows(c, row.value) {
+			return false
+		}
+		d.cageLeft[c] -= row.value + 1
+		d.cageEmpty[c]--
+		d.cageUsed[c] |= 1 << uint(row.value)
+	}
+	d.state.Values[row.cell] = row.value
+	return true
+}
+
+// allows returns true if all the constraints allow v in the cell given the values placed so far
+func (d *dlx) allows(cell, v int) bool {
+	for _, k := range d.constraints {
+		if !k.Allows(d.state, Cell{Row: cell / d.size, Col: cell % d.size}, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// cageAllows returns true if v is not in the cage c yet and the other empty cells of the cage can still add up to
+// the rest of its sum with values not in the cage
+func (d *dlx) cageAllows(c, v int) bool {
+	bit := uint64(1) << uint(v)
+	if d.cageUsed[c]&bit != 0 {
+		return false
+	}
+	all := uint64(1)<<uint(d.size) - 1
+	return distinctSum(all&^d.cageUsed[c]&^bit, d.cageEmpty[c]-1, d.cageLeft[c]-v-1)
+}
+
+// hideCageRows hides the rows of the empty cells sharing a cage with the cell whose value the cage does not allow
+// anymore, see unhideRows
+func (d *dlx) hideCageRows(cell int) {
+	c := d.cellCage[cell]
+	if c == -1 {
+		return
+	}
+	d.hideRows(d.cages[c], func(other, v int) bool { return d.cageAllows(c, v) })
+}
+
+// hideConstrainedRows hides the rows of the empty cells related to the cell by a constraint whose value the
+// constraint does not allow anymore, see unhideRows
+func (d *dlx) hideConstrainedRows(cell int) {
+	for _, k := range d.cellConstraints[cell] {
+		constraint := d.constraints[k]
+		d.hideRows(d.related[k][cell], func(other, v int) bool {
+			return constraint.Allows(d.state, Cell{Row: other / d.size, Col: other % d.size}, v)
+		})
+	}
+}
+
+// hideRows hides the rows of the empty cells among the given ones whose value is not allowed, see unhideRows
+func (d *dlx) hideRows(cells []int, allowed func(cell, v int) bool) {
+	for _, other := range cells {
+		header := 1 + other
+		if d.right[d.left[header]] != header {
+			// the column of a filled cell is covered
+			continue
+		}
+		for i := d.down[header]; i != header; i = d.down[i] {
+			if allowed(other, d.rows[d.row[i]].value) {
+				continue
+			}
+			j := i
+			for {
+				d.down[d.up[j]] = d.down[j]
+				d.up[d.down[j]] = d.up[j]
+				d.count[d.column[j]]--
+				if j = d.right[j]; j == i {
+					break
+				}
+			}
+			d.hidden = append(d.hidden, i)
+		}
+	}
+}
+
+// unhideRows restores the rows hidden since the length of d.hidden was n, in the reverse order
+func (d *dlx) unhideRows(n int) {
+	for k := len(d.hidden) - 1; k >= n; k-- {
+		i := d.hidden[k]
+		j := i
+		for {
+			d.count[d.column[j]]++
+			d.down[d.up[j]] = j
+			d.up[d.down[j]] = j
+			if j = d.right[j]; j == i {
+				break
+			}
+		}
+	}
+	d.hidden = d.hidden[:n]
+}
+
+// distinctSum returns true if k distinct values of the mask add up to sum, the value v counting as v+1
+func distinctSum(mask uint64, k, sum int) bool {
+	if k == 0 {
+		return sum == 0
+	}
+	for ; mask != 0; mask &= mask - 1 {
+		v := bits.TrailingZeros64(mask) + 1
+		// the values are tried in increasing order, the k-1 other ones are greater than v
+		if k*v+k*(k-1)/2 > sum {
+			return false
+		}
+		if distinctSum(mask&(mask-1), k-1, sum-v) {
+			return true
+		}
+	}
+	return false
+}
+
+// unplace undoes place(row)
+func (d *dlx) unplace(row dlxRow) {
+	d.state.Values[row.cell] = -1
+	c := d.cellCage[row.cell]
+	if c == -1 {
+		return
+	}
+	d.cageLeft[c] += row.value + 1
+	d.cageEmpty[c]++
+	d.cageUsed[c] &^= 1 << uint(row.value)
+}
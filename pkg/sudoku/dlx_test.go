@@ -0,0 +1,95 @@
+package sudoku
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DLXSolver", func() {
+	It("solves a hard 9x9 puzzle", func() {
+		sG := parseGrid(9, 3, hardPuzzle)
+		stats, err := sG.SolveWith(context.Background(), DLXSolver{})
+		Expect(err).To(BeNil())
+		Expect(stats.Nodes).To(BeNumerically(">", 0))
+		Expect(isSolved(sG)).To(BeTrue())
+		Expect(string(sG.Grid[0])).To(Equal("812753649"))
+	})
+
+	It("solves large grids", func() {
+		sG := parseGrid(25, 5, puzzle25x25)
+		_, err := sG.SolveWith(context.Background(), DLXSolver{})
+		Expect(err).To(BeNil())
+		Expect(isSolved(sG)).To(BeTrue())
+	})
+
+	It("detects contradicting clues", func() {
+		sG, err := New(9, 3, 3)
+		Expect(err).To(BeNil())
+		sG.Set(0, 0, '5')
+		sG.Set(8, 0, '5')
+
+		_, err = sG.SolveWith(context.Background(), DLXSolver{})
+		Expect(err).NotTo(BeNil())
+		count, _, _ := sG.CountSolutionsWith(context.Background(), DLXSolver{}, 0)
+		Expect(count).To(Equal(0))
+	})
+
+	Context("Cross-checking with the backtracking solver", func() {
+		It("finds the same solutions", func() {
+			for seed := int64(0); seed < 20; seed++ {
+				sG := blankCells(patternGrid(9, 3, 3), 0.5, seed)
+
+				backtracking, _, _ := sG.FindSolutionsWith(context.Background(), BacktrackingSolver{}, 0)
+				dlx, _, _ := sG.FindSolutionsWith(context.Background(), DLXSolver{}, 0)
+				Expect(dlx).To(HaveLen(len(backtracking)))
+
+				seen := map[string]bool{}
+				for _, solution := range backtracking {
+					seen[gridString(solution)] = true
+				}
+				for _, solution := range dlx {
+					Expect(isSolved(solution)).To(BeTrue())
+					Expect(seen).To(HaveKey(gridString(solution)))
+				}
+			}
+		})
+
+		It("agrees on the uniqueness of the puzzles", func() {
+			for _, sG := range []*SudokuGrid{
+				parseGrid(9, 3, hardPuzzle),
+				parseGrid(16, 4, puzzle16x16),
+				blankCells(patternGrid(16, 4, 4), 0.4, 1),
+			} {
+				dlx, _, _ := sG.CountSolutionsWith(context.Background(), DLXSolver{}, 2)
+				backtracking, _, _ := sG.CountSolutionsWith(context.Background(), BacktrackingSolver{}, 2)
+				Expect(dlx).To(Equal(backtracking))
+			}
+		})
+	})
+})
+
+// gridString returns the cells of the SudokuGrid in row-major order
+func gridString(sG *SudokuGrid) string {
+	var cells []rune
+	for _, row := range sG.Grid {
+		cells = append(cells, row...)
+	}
+	return string(cells)
+}
+
+func BenchmarkSolveDLX(b *testing.B) {
+	for _, input := range benchmarkInputs {
+		puzzle := parseGrid(input.size, input.partition, input.cells)
+		b.Run(input.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sG := puzzle.Clone()
+				if _, err := sG.SolveWith(context.Background(), DLXSolver{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoSolution is returned when a puzzle cannot be completed
+	ErrNoSolution = errors.New("no solution exists")
+	// ErrInvalidDimensions is returned when the size of a grid does not match its subgrids, its rows or its pencil marks
+	ErrInvalidDimensions = errors.New("invalid dimensions")
+	// ErrOutOfBounds is returned when the coordinates of a cell are outside of the grid, see OutOfBoundsError
+	ErrOutOfBounds = errors.New("cell coordinates out of bounds")
+	// ErrInvalidSymbol is returned when a value or an alphabet is not valid for the grid
+	ErrInvalidSymbol = errors.New("invalid symbol")
+	// ErrInvalidCage is returned when a cage of a Killer Sudoku overlaps another one or has an impossible sum
+	ErrInvalidCage = errors.New("invalid cage")
+	// ErrInvalidRegion is returned when the regions of a jigsaw sudoku do not split the grid into connected subgrids
+	ErrInvalidRegion = errors.New("invalid region")
+	// ErrInvalidConstraint is returned when a constraint of a variant is unknown or does not fit in the grid
+	ErrInvalidConstraint = errors.New("invalid constraint")
+	// ErrInvalidOverlap is returned when the overlaps of a MultiGrid do not lay its grids out consistently
+	ErrInvalidOverlap = errors.New("invalid overlap")
+	// ErrConflict is returned when a unit holds the same value twice or a constraint is broken, see ValidationError
+	ErrConflict = errors.New("conflicting values")
+	// ErrInvalidVariant is returned when the variant of a grid is unknown, see Variants
+	ErrInvalidVariant = errors.New("invalid variant")
+	// ErrUnknownSolver is returned when no Solver is registered under the requested name, see Solvers
+	ErrUnknownSolver = errors.New("invalid solver")
+	// ErrLevelNotReached is returned when no puzzle graded at the requested level is found, see LevelError
+	ErrLevelNotReached = errors.New("level not reached")
+	// ErrTimeout is returned when the context is done before the work is over, the context error is wrapped too
+	ErrTimeout = errors.New("timeout")
+)
+
+// OutOfBoundsError is returned when accessing a cell outside of the grid, it matches ErrOutOfBounds
+type OutOfBoundsError struct {
+	Row, Col int
+}
+
+func (e *OutOfBoundsError) Error() string {
+	return fmt.Sprintf("%v (%d, %d)", ErrOutOfBounds, e.Row, e.Col)
+}
+
+func (e *OutOfBoundsError) Is(target error) bool {
+	return target == ErrOutOfBounds
+}
+
+// LevelError is returned when no puzzle graded at the requested level is found in the given number of attempts,
+// the grid then holds the closest puzzle, graded Closest. It matches ErrLevelNotReached.
+type LevelError struct {
+	Level    string
+	Attempts int
+	Closest  *Difficulty
+}
+
+func (e *LevelError) Error() string {
+	return fmt.Sprintf("could not generate a puzzle graded %s in %d attempts, the closest one is graded %s with a score of %d",
+		e.Level, e.Attempts, e.Closest.Level, e.Closest.Score)
+}
+
+func (e *LevelError) Is(target error) bool {
+	return target == ErrLevelNotReached
+}
+
+// Is matches ErrInvalidSymbol and ErrConflict if the grid holds values of the kind
+func (e *ValidationError) Is(target error) bool {
+	return (target == ErrInvalidSymbol && len(e.InvalidSymbols) > 0) ||
+		(target == ErrConflict && (len(e.Conflicts) > 0 || len(e.CageSums) > 0 || len(e.Violations) > 0))
+}
+
+// timeoutError wraps the error of a context done before the work is over, it matches ErrTimeout
+type timeoutError struct {
+	err error
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrTimeout, e.err)
+}
+
+func (e *timeoutError) Unwrap() error {
+	return e.err
+}
+
+func (e *timeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// contextError returns the error of ctx wrapped to match ErrTimeout, nil if ctx is not done
+func contextError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &timeoutError{err: err}
+	}
+	return nil
+}
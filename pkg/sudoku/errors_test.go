@@ -0,0 +1,113 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+	It("reports puzzles without solution", func() {
+		sG, err := New(4, 2, 2)
+		Expect(err).To(BeNil())
+		sG.Set(0, 0, '1')
+		sG.Set(0, 1, '2')
+		sG.Set(0, 2, '3')
+		sG.Set(2, 3, '4')
+
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			_, err = sG.Clone().SolveWith(context.Background(), s)
+			Expect(err).To(MatchError(ErrNoSolution))
+		}
+		_, err = sG.Clone().SolveLogically()
+		Expect(err).To(MatchError(ErrNoSolution))
+	})
+
+	It("reports invalid dimensions", func() {
+		_, err := New(8, 2, 2)
+		Expect(err).To(MatchError(ErrInvalidDimensions))
+
+		sG := patternGrid(4, 2, 2)
+		sG.Grid[3] = sG.Grid[3][1:]
+		Expect(sG.Valid()).To(MatchError(ErrInvalidDimensions))
+	})
+
+	It("reports cells out of bounds", func() {
+		sG := patternGrid(4, 2, 2)
+		err := sG.Set(4, 1, '1')
+		Expect(err).To(MatchError(ErrOutOfBounds))
+		var bounds *OutOfBoundsError
+		Expect(errors.As(err, &bounds)).To(BeTrue())
+		Expect(*bounds).To(Equal(OutOfBoundsError{Row: 4, Col: 1}))
+	})
+
+	It("reports invalid symbols", func() {
+		_, err := SymbolSet("ABCA", 4)
+		Expect(err).To(MatchError(ErrInvalidSymbol))
+		_, err = NewWithSymbols(4, 2, 2, "A.BC")
+		Expect(err).To(MatchError(ErrInvalidSymbol))
+
+		sG := patternGrid(4, 2, 2)
+		sG.Set(0, 0, 'Z')
+		err = sG.Validate()
+		Expect(err).To(MatchError(ErrInvalidSymbol))
+		Expect(errors.Is(err, ErrConflict)).To(BeFalse())
+	})
+
+	It("reports grids the solvers cannot lay out", func() {
+		// SolveWith does not validate the grid, the unknown symbol is only found laying it out
+		sG := patternGrid(4, 2, 2)
+		sG.Grid[0][0] = 'Z'
+		sG.Grid[0][1] = EMPTY_CELL
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			_, err = sG.Clone().SolveWith(context.Background(), s)
+			Expect(err).To(MatchError(ErrInvalidSymbol))
+			_, _, err = sG.CountSolutionsWith(context.Background(), s, 2)
+			Expect(err).To(MatchError(ErrInvalidSymbol))
+			_, _, err = sG.FindSolutionsWith(context.Background(), s, 2)
+			Expect(err).To(MatchError(ErrInvalidSymbol))
+		}
+
+		symbols := make([]rune, 81)
+		for i := range symbols {
+			symbols[i] = rune(0x100 + i)
+		}
+		large, err := NewWithSymbols(81, 9, 9, string(symbols))
+		Expect(err).To(BeNil())
+		_, err = large.SolveWith(context.Background(), DLXSolver{})
+		Expect(err).To(MatchError(ErrInvalidDimensions))
+	})
+
+	It("reports unknown variants and solvers", func() {
+		_, err := GenerateSudokuGrid(4, 2, 2, WithVariant("windoku"))
+		Expect(err).To(MatchError(ErrInvalidVariant))
+		_, err = GetSolver("bogosort")
+		Expect(err).To(MatchError(ErrUnknownSolver))
+	})
+
+	It("reports conflicting values", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		sG.Set(1, 1, '3')
+		err := sG.Validate()
+		Expect(err).To(MatchError(ErrConflict))
+		Expect(errors.Is(err, ErrInvalidSymbol)).To(BeFalse())
+	})
+
+	It("reports timeouts along with the context error", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := parseGrid(9, 3, hardPuzzle).SolveContext(ctx)
+		Expect(err).To(MatchError(ErrTimeout))
+		Expect(err).To(MatchError(context.Canceled))
+
+		_, err = GenerateSudokuGridContext(ctx, 9, 3, 3)
+		Expect(err).To(MatchError(ErrTimeout))
+	})
+})
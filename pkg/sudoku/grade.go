@@ -0,0 +1,103 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+)
+
+// maxLogicalScore is the score of the puzzles the logical solver gets stuck on, which need guessing
+const maxLogicalScore = 100
+
+// gradeLevels maps scores to the difficulty levels, a puzzle belongs to the first level whose bound
+// is greater than its score
+var gradeLevels = []struct {
+	name  string
+	bound int
+}{
+	{"easy", 20},
+	{"medium", 40},
+	{"hard", 60},
+	{"extreme", maxLogicalScore},
+}
+
+// Difficulty is the grade of a puzzle, based on the techniques a person needs to solve it
+type Difficulty struct {
+	Score      int               `json:"score"`
+	Level      string            `json:"level"`
+	Hardest    Technique         `json:"hardest,omitempty"`
+	Techniques map[Technique]int `json:"techniques"` // number of steps using each technique
+	Stuck      bool              `json:"stuck"`      // true if the puzzle cannot be solved without guessing
+}
+
+// Grade rates how hard the SudokuGrid is for a person by solving a copy of it with SolveLogically.
+// The score is the weight of the hardest technique needed, plus one point for each extra use of it, up to 9.
+// Puzzles that cannot be solved without guessing score maxLogicalScore and are graded "robot".
+func Grade(sG *SudokuGrid) (*Difficulty, error) {
+	return GradeContext(context.Background(), sG)
+}
+
+// GradeContext is like Grade but gives up with the context error once ctx is done
+func GradeContext(ctx context.Context, sG *SudokuGrid) (*Difficulty, error) {
+	solution, err := sG.Clone().SolveLogicallyContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Difficulty{Techniques: map[Technique]int{}, Stuck: solution.Stuck}
+	hardest := -1
+	for _, step := range solution.Steps {
+		d.Techniques[step.Technique]++
+		if w := techniqueWeight(step.Technique); w > hardest {
+			hardest = w
+			d.Hardest = step.Technique
+		}
+	}
+
+	switch {
+	case d.Stuck:
+		d.Score = maxLogicalScore
+	case hardest >= 0:
+		extra := d.Techniques[d.Hardest] - 1
+		if extra > 9 {
+			extra = 9
+		}
+		d.Score = hardest + extra
+	}
+	d.Level = gradeLevel(d.Score)
+	return d, nil
+}
+
+// techniqueWeight returns the weight of the technique, or -1 if it is unknown
+func techniqueWeight(t Technique) int {
+	for _, known := range techniques {
+		if known.name == t {
+			return known.weight
+		}
+	}
+	return -1
+}
+
+// gradeLevel returns the difficulty level matching the score
+func gradeLevel(score int) string {
+	for _, level := range gradeLevels {
+		if score < level.bound {
+			return level.name
+		}
+	}
+	return "robot"
+}
+
+// gradeBand returns the range [low, high) of the scores graded at the given level
+func gradeBand(level string) (int, int, error) {
+	low := 0
+	for _, l := range gradeLevels {
+		if l.name == level {
+			return low, l.bound, nil
+		}
+		low = l.bound
+	}
+	if level == "robot" {
+		return maxLogicalScore, maxLogicalScore + 1, nil
+	}
+	return 0, 0, fmt.Errorf("invalid level %q: must be one of the graded levels (easy, medium, hard, extreme, robot)", level)
+}
@@ -0,0 +1,110 @@
+package sudoku
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const mediumPuzzle = ".6...7..9..73.9...3..5...2..5.8.2...8.....6.1.9.....7........3...69..2..9...85..."
+
+const extremePuzzle = "6..28...32.4..36...7.......16............9...7..1...48...4.1.97.2.3.....3..8....1"
+
+var _ = Describe("Grading", func() {
+	It("grades a puzzle solved with singles as easy", func() {
+		d, err := Grade(parseGrid(9, 3, easyPuzzle))
+		Expect(err).To(BeNil())
+		Expect(d.Level).To(Equal("easy"))
+		Expect(d.Stuck).To(BeFalse())
+		Expect(d.Techniques).To(Equal(map[Technique]int{NakedSingle: 49}))
+		Expect(d.Hardest).To(Equal(NakedSingle))
+		Expect(d.Score).To(Equal(10))
+	})
+
+	It("scores a puzzle by its hardest technique and how often it is used", func() {
+		d, err := Grade(parseGrid(9, 3, mediumPuzzle))
+		Expect(err).To(BeNil())
+		Expect(d.Hardest).To(Equal(PointingPair))
+		Expect(d.Techniques[PointingPair]).To(Equal(1))
+		Expect(d.Score).To(Equal(20))
+		Expect(d.Level).To(Equal("medium"))
+
+		d, err = Grade(parseGrid(9, 3, extremePuzzle))
+		Expect(err).To(BeNil())
+		Expect(d.Hardest).To(Equal(XYWing))
+		Expect(d.Techniques[XYWing]).To(Equal(3))
+		Expect(d.Score).To(Equal(62))
+		Expect(d.Level).To(Equal("extreme"))
+	})
+
+	It("uses the easiest technique available at each step", func() {
+		for i := 1; i < len(techniques); i++ {
+			Expect(techniques[i].weight).To(BeNumerically(">=", techniques[i-1].weight), "%s", techniques[i].name)
+		}
+
+		// once the singles are played, both a naked triple and a pointing pair apply to the medium puzzle
+		sG := parseGrid(9, 3, mediumPuzzle)
+		for {
+			hint, err := sG.Hint()
+			Expect(err).To(BeNil())
+			if len(hint.Step.Placements) == 0 {
+				break
+			}
+			p := hint.Step.Placements[0]
+			sG.Set(p.Row, p.Col, p.Value)
+		}
+		lg, err := newLogic(sG)
+		Expect(err).To(BeNil())
+		Expect(lg.nakedSubset(3, NakedTriple)).NotTo(BeNil())
+
+		lg, err = newLogic(sG)
+		Expect(err).To(BeNil())
+		step, err := lg.next()
+		Expect(err).To(BeNil())
+		Expect(step.Technique).To(Equal(PointingPair))
+	})
+
+	It("grades puzzles needing guesses as robot", func() {
+		d, err := Grade(parseGrid(9, 3, hardPuzzle))
+		Expect(err).To(BeNil())
+		Expect(d.Stuck).To(BeTrue())
+		Expect(d.Score).To(Equal(maxLogicalScore))
+		Expect(d.Level).To(Equal("robot"))
+	})
+
+	It("leaves the puzzle untouched", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		_, err := Grade(sG)
+		Expect(err).To(BeNil())
+		Expect(sG.Grid).To(Equal(parseGrid(9, 3, easyPuzzle).Grid))
+	})
+
+	It("returns an error when the puzzle has no solution", func() {
+		sG, err := New(9, 3, 3)
+		Expect(err).To(BeNil())
+		sG.Set(0, 0, '5')
+		sG.Set(0, 8, '5')
+		d, err := Grade(sG)
+		Expect(err).NotTo(BeNil())
+		Expect(d).To(BeNil())
+	})
+
+	It("gives up once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		d, err := GradeContext(ctx, parseGrid(9, 3, mediumPuzzle))
+		Expect(err).To(MatchError(ErrTimeout))
+		Expect(d).To(BeNil())
+	})
+
+	It("maps scores to levels", func() {
+		Expect(gradeLevel(0)).To(Equal("easy"))
+		Expect(gradeLevel(19)).To(Equal("easy"))
+		Expect(gradeLevel(20)).To(Equal("medium"))
+		Expect(gradeLevel(40)).To(Equal("hard"))
+		Expect(gradeLevel(60)).To(Equal("extreme"))
+		Expect(gradeLevel(99)).To(Equal("extreme"))
+		Expect(gradeLevel(maxLogicalScore)).To(Equal("robot"))
+	})
+})
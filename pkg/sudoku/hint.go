@@ -0,0 +1,100 @@
+package sudoku
+
+import (
+	"context"
+	"sort"
+)
+
+// Hint is the help given to a player stuck on a puzzle
+type Hint struct {
+	Step      *Step  `json:"step,omitempty"`      // next deduction, nil if the grid is solved, holds a mistake or no technique applies
+	Conflicts []Cell `json:"conflicts,omitempty"` // cells holding a mistake, in row-major order
+	Solved    bool   `json:"solved"`
+}
+
+// Hint returns the next logical move to make on the SudokuGrid, or the cells holding a mistake if the grid
+// cannot be completed anymore. The SudokuGrid is not modified.
+func (sG *SudokuGrid) Hint() (*Hint, error) {
+	return sG.HintContext(context.Background())
+}
+
+// HintContext is like Hint but gives up with the context error once ctx is done.
+// The next move is found by the logical solver from the pencil marks implied by the values of the grid,
+// an elimination is thus suggested again until the player places a value.
+func (sG *SudokuGrid) HintContext(ctx context.Context) (*Hint, error) {
+	if conflicts := sG.conflicts(); len(conflicts) > 0 {
+		return &Hint{Conflicts: conflicts}, nil
+	}
+
+	count, _, err := sG.CountSolutionsWith(ctx, DLXSolver{}, 1)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		conflicts, err := sG.mistakes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &Hint{Conflicts: conflicts}, nil
+	}
+
+	lg, err := newLogic(sG)
+	if err != nil {
+		return nil, err
+	}
+	if lg.solved() {
+		return &Hint{Solved: true}, nil
+	}
+	step, err := lg.next()
+	if err != nil {
+		return nil, err
+	}
+	return &Hint{Step: step}, nil
+}
+
+// conflicts returns the cells holding the same value as another cell of one of their units or breaking a
+// constraint, in row-major order
+func (sG *SudokuGrid) conflicts() []Cell {
+	var cells []Cell
+	for _, c := range sG.duplicates() {
+		cells = append(cells, c.Cell)
+	}
+	for _, v := range sG.violations() {
+		cells = append(cells, v.Cell)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Row != cells[j].Row {
+			return cells[i].Row < cells[j].Row
+		}
+		return cells[i].Col < cells[j].Col
+	})
+
+	var res []Cell
+	for _, c := range cells {
+		if len(res) == 0 || res[len(res)-1] != c {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// mistakes returns the filled cells of an unsolvable SudokuGrid whose removal makes it solvable again.
+// With a single wrong value, it is one of them, the other ones are the clues it contradicts.
+func (sG *SudokuGrid) mistakes(ctx context.Context) ([]Cell, error) {
+	var res []Cell
+	for _, c := range sG.filledCells() {
+		clone := sG.Clone()
+		clone.Set(c.x, c.y, EMPTY_CELL)
+		count, _, err := clone.CountSolutionsWith(ctx, DLXSolver{}, 1)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			res = append(res, Cell{Row: c.x, Col: c.y})
+		}
+	}
+	if len(res) == 0 {
+		return nil, ErrNoSolution
+	}
+	return res, nil
+}
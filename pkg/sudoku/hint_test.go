@@ -0,0 +1,90 @@
+package sudoku
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hint", func() {
+	It("explains the next logical move", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		hint, err := sG.Hint()
+		Expect(err).To(BeNil())
+		Expect(hint.Solved).To(BeFalse())
+		Expect(hint.Conflicts).To(BeEmpty())
+		Expect(hint.Step).NotTo(BeNil())
+		Expect(hint.Step.Technique).To(Equal(NakedSingle))
+		Expect(hint.Step.Placements).To(HaveLen(1))
+		Expect(hint.Step.Description).NotTo(BeEmpty())
+
+		// the hint is consistent with the solution, and the grid is left untouched
+		solution := parseGrid(9, 3, easyPuzzle)
+		Expect(solution.Solve()).To(Succeed())
+		p := hint.Step.Placements[0]
+		Expect(p.Value).To(Equal(solution.Grid[p.Row][p.Col]))
+		Expect(sG.Grid).To(Equal(parseGrid(9, 3, easyPuzzle).Grid))
+	})
+
+	It("suggests eliminations when no value can be placed yet", func() {
+		hint, err := parseGrid(9, 3, mediumPuzzle).Hint()
+		Expect(err).To(BeNil())
+		Expect(hint.Step).NotTo(BeNil())
+
+		// play the hints until the pointing pair is needed
+		sG := parseGrid(9, 3, mediumPuzzle)
+		for hint.Step.Technique != PointingPair {
+			Expect(hint.Step.Placements).To(HaveLen(1))
+			p := hint.Step.Placements[0]
+			sG.Set(p.Row, p.Col, p.Value)
+			hint, err = sG.Hint()
+			Expect(err).To(BeNil())
+			Expect(hint.Step).NotTo(BeNil())
+		}
+		Expect(hint.Step.Placements).To(BeEmpty())
+		Expect(hint.Step.Eliminations).NotTo(BeEmpty())
+	})
+
+	It("reports a solved grid", func() {
+		hint, err := patternGrid(9, 3, 3).Hint()
+		Expect(err).To(BeNil())
+		Expect(hint.Solved).To(BeTrue())
+		Expect(hint.Step).To(BeNil())
+	})
+
+	It("reports the cells holding the same value in a unit", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		// r1c3 and r2c4 hold a 3, the player puts another one in their box and row
+		sG.Set(1, 1, '3')
+		hint, err := sG.Hint()
+		Expect(err).To(BeNil())
+		Expect(hint.Step).To(BeNil())
+		Expect(hint.Conflicts).To(Equal([]Cell{{0, 2}, {1, 1}, {1, 3}}))
+	})
+
+	It("reports a value contradicting the solution", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		solution := sG.Clone()
+		Expect(solution.Solve()).To(Succeed())
+
+		// put a value allowed by the units of the cell but different from the solution
+		var wrong Cell
+		for _, c := range sG.missingCells() {
+			for _, v := range sG.allowedValues {
+				if v != solution.Grid[c.x][c.y] && sG.canSet(c.x, c.y, v) {
+					sG.Set(c.x, c.y, v)
+					wrong = Cell{Row: c.x, Col: c.y}
+					break
+				}
+			}
+			if sG.Grid[c.x][c.y] != EMPTY_CELL {
+				break
+			}
+		}
+		Expect(sG.conflicts()).To(BeEmpty())
+
+		hint, err := sG.Hint()
+		Expect(err).To(BeNil())
+		Expect(hint.Step).To(BeNil())
+		Expect(hint.Conflicts).To(ContainElement(wrong))
+	})
+})
@@ -0,0 +1,90 @@
+package sudoku
+
+import (
+	"fmt"
+)
+
+// HyperRegions returns the windows of a Hyper Sudoku, a.k.a. Windoku: subgrids of partitionHeight x partitionWidth
+// cells set one cell apart from the edges of the grid and from each other, the four 3x3 windows of a 9x9 grid
+// starting at r2c2, r2c6, r6c2 and r6c6. Each window is listed in row-major order.
+func HyperRegions(size, partitionWidth, partitionHeight int) [][]Cell {
+	if partitionWidth <= 0 || partitionHeight <= 0 {
+		return nil
+	}
+	var regions [][]Cell
+	for top := 1; top+partitionHeight < size; top += partitionHeight + 1 {
+		for left := 1; left+partitionWidth < size; left += partitionWidth + 1 {
+			var region []Cell
+			for x := top; x < top+partitionHeight; x++ {
+				for y := left; y < left+partitionWidth; y++ {
+					region = append(region, Cell{Row: x, Col: y})
+				}
+			}
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+// WithHyper generates Hyper Sudoku grids, whose windows given by HyperRegions also hold every symbol once
+func WithHyper() GeneratorOption {
+	return func(o *generatorOptions) {
+		o.hyper = true
+	}
+}
+
+// WithExtraRegions generates grids whose given regions also hold every symbol once, see SudokuGrid.ExtraRegions
+func WithExtraRegions(regions ...[]Cell) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.extraRegions = append(o.extraRegions, regions...)
+	}
+}
+
+// validExtraRegions returns an error if an extra region does not list Size distinct cells of the grid
+func (sG *SudokuGrid) validExtraRegions() error {
+	for i, region := range sG.ExtraRegions {
+		if len(region) != sG.Size {
+			return fmt.Errorf("%w: extra region %d has %d cells, must have %d", ErrInvalidRegion, i, len(region), sG.Size)
+		}
+		seen := make(map[Cell]bool, len(region))
+		for _, c := range region {
+			if err := sG.isValidIndex(c.Row, c.Col); err != nil {
+				return err
+			}
+			if seen[c] {
+				return fmt.Errorf("%w: r%dc%d is given twice in extra region %d", ErrInvalidRegion, c.Row+1, c.Col+1, i)
+			}
+			seen[c] = true
+		}
+	}
+	return nil
+}
+
+// extraRegionsAllow returns true if no extra region holding the cell (x, y) holds val in another cell
+func (sG *SudokuGrid) extraRegionsAllow(x, y int, val rune) bool {
+	for _, region := range sG.ExtraRegions {
+		if indexOf(region, Cell{Row: x, Col: y}) == -1 {
+			continue
+		}
+		for _, c := range region {
+			if (c.Row != x || c.Col != y) && sG.Grid[c.Row][c.Col] == val {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// shaded returns whether each cell of the grid lies in an extra region in row-major order, nil if there are none
+func (sG *SudokuGrid) shaded() []bool {
+	if len(sG.ExtraRegions) == 0 {
+		return nil
+	}
+	res := make([]bool, sG.Size*sG.Size)
+	for _, region := range sG.ExtraRegions {
+		for _, c := range region {
+			res[c.Row*sG.Size+c.Col] = true
+		}
+	}
+	return res
+}
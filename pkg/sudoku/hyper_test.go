@@ -0,0 +1,115 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hyper", func() {
+	DescribeTable("lays the windows out one cell apart",
+		func(size, partitionWidth, partitionHeight int, corners []Cell) {
+			regions := HyperRegions(size, partitionWidth, partitionHeight)
+			Expect(regions).To(HaveLen(len(corners)))
+			for i, region := range regions {
+				Expect(region).To(HaveLen(size))
+				Expect(region[0]).To(Equal(corners[i]))
+			}
+		},
+		Entry("4x4", 4, 2, 2, []Cell{{1, 1}}),
+		Entry("6x6", 6, 3, 2, []Cell{{1, 1}}),
+		Entry("9x9", 9, 3, 3, []Cell{{1, 1}, {1, 5}, {5, 1}, {5, 5}}),
+		Entry("12x12", 12, 4, 3, []Cell{{1, 1}, {1, 6}, {5, 1}, {5, 6}}),
+	)
+
+	DescribeTable("generates grids whose windows hold every symbol once",
+		func(size, partitionWidth, partitionHeight int) {
+			sG, err := GenerateSudokuGrid(size, partitionWidth, partitionHeight, WithHyper())
+			Expect(err).To(BeNil())
+			Expect(sG.ExtraRegions).To(Equal(HyperRegions(size, partitionWidth, partitionHeight)))
+			Expect(isSolved(sG)).To(BeTrue())
+			Expect(sG.Validate()).To(Succeed())
+		},
+		Entry("4x4", 4, 2, 2),
+		Entry("6x6", 6, 3, 2),
+		Entry("9x9", 9, 3, 3),
+		Entry("12x12", 12, 4, 3),
+	)
+
+	It("generates puzzles whose unique solution follows the windows", func() {
+		sG, err := GenerateSudokuGrid(9, 3, 3, WithHyper())
+		Expect(err).To(BeNil())
+		solution := sG.Clone()
+		Expect(sG.SetGridToLevel("medium", WithGradedDifficulty(DefaultGradingAttempts))).To(Succeed())
+		Expect(sG.IsUnique()).To(BeTrue())
+
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			puzzle := sG.Clone()
+			_, err = puzzle.SolveWith(context.Background(), s)
+			Expect(err).To(BeNil())
+			Expect(puzzle.Grid).To(Equal(solution.Grid))
+		}
+		puzzle := sG.Clone()
+		_, err = puzzle.SolveLogically()
+		Expect(err).To(BeNil())
+		Expect(puzzle.Grid).To(Equal(solution.Grid))
+	})
+
+	It("reports a value repeated in an extra region", func() {
+		sG, err := New(4, 2, 2)
+		Expect(err).To(BeNil())
+		sG.ExtraRegions = HyperRegions(4, 2, 2)
+		sG.Set(1, 1, '1')
+		Expect(sG.canSet(2, 2, '1')).To(BeFalse())
+		sG.Set(2, 2, '1')
+
+		err = sG.Validate()
+		Expect(err).To(MatchError(ErrConflict))
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Conflicts).To(Equal([]Conflict{
+			{Candidate: Candidate{Cell: Cell{Row: 1, Col: 1}, Value: '1'}, Unit: "extra region", Index: 0},
+			{Candidate: Candidate{Cell: Cell{Row: 2, Col: 2}, Value: '1'}, Unit: "extra region", Index: 0},
+		}))
+		_, err = sG.SolveWith(context.Background(), DLXSolver{})
+		Expect(err).To(MatchError(ErrNoSolution))
+	})
+
+	DescribeTable("rejects invalid extra regions",
+		func(regions [][]Cell, expected error) {
+			sG, err := New(4, 2, 2)
+			Expect(err).To(BeNil())
+			sG.ExtraRegions = regions
+			Expect(sG.Valid()).To(MatchError(expected))
+		},
+		Entry("too small", [][]Cell{{{0, 0}, {1, 1}, {2, 2}}}, ErrInvalidRegion),
+		Entry("outside of the grid", [][]Cell{{{0, 0}, {1, 1}, {2, 2}, {4, 4}}}, ErrOutOfBounds),
+		Entry("with a cell given twice", [][]Cell{{{0, 0}, {1, 1}, {2, 2}, {1, 1}}}, ErrInvalidRegion),
+	)
+
+	It("cannot lay windows out on jigsaw regions", func() {
+		_, err := GenerateSudokuGrid(9, 0, 0, WithJigsaw(), WithHyper())
+		Expect(err).To(MatchError(ErrInvalidRegion))
+	})
+
+	It("shades the cells of the extra regions", func() {
+		sG, err := New(4, 2, 2)
+		Expect(err).To(BeNil())
+		sG.ExtraRegions = HyperRegions(4, 2, 2)
+		sG.Set(1, 1, '3')
+		Expect(strings.Split(sG.ToStringPrettify(), "\n")[1]).To(Equal(" . [3]|[.] . "))
+
+		b, err := json.Marshal(sG)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(ContainSubstring(`"extraRegions":[[{"row":1,"col":1},{"row":1,"col":2},{"row":2,"col":1},{"row":2,"col":2}]]`))
+		parsed := &SudokuGrid{}
+		Expect(json.Unmarshal(b, parsed)).To(Succeed())
+		Expect(parsed.ExtraRegions).To(Equal(sG.ExtraRegions))
+	})
+})
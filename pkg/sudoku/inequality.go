@@ -0,0 +1,220 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	// maxHideFailures is the number of signs or values shown again, the puzzle becoming ambiguous without them,
+	// after which the generator stops trying to hide more of them: a failure usually takes the whole budget of the
+	// uniqueness check, and failures are most of the attempts once the puzzle is close to minimal
+	maxHideFailures = 8
+	// maxUnguidedSize is the size of the largest grids turned into puzzles starting from no givens at all, the
+	// uniqueness checks of larger grids seldom succeed within their budget and their generation takes minutes
+	maxUnguidedSize = 9
+)
+
+// Inequality is a sign between two adjacent cells of a comparison sudoku, a.k.a. Greater Than Sudoku:
+// the value of Less is smaller than the value of Greater. It is enforced as a Constraint.
+type Inequality struct {
+	Less    Cell `json:"less"`
+	Greater Cell `json:"greater"`
+}
+
+func (Inequality) Type() string { return "inequality" }
+
+func (in Inequality) String() string {
+	return fmt.Sprintf("r%dc%d<r%dc%d", in.Less.Row+1, in.Less.Col+1, in.Greater.Row+1, in.Greater.Col+1)
+}
+
+// Valid checks that both cells are in the grid and share a side
+func (in Inequality) Valid(size int) error {
+	if err := validCells(size, []Cell{in.Less, in.Greater}); err != nil {
+		return err
+	}
+	if !adjacent(in.Less, in.Greater, false) {
+		return fmt.Errorf("%w: the cells of an inequality must share a side", ErrInvalidConstraint)
+	}
+	return nil
+}
+
+func (in Inequality) Related(size int, cell Cell) []Cell {
+	return in.thermometer().Related(size, cell)
+}
+
+// Allows checks the value against the other cell, or leaves room for a smaller or a greater value if it is empty
+func (in Inequality) Allows(s *State, cell Cell, v int) bool {
+	return in.thermometer().Allows(s, cell, v)
+}
+
+// Prune keeps the candidates of Less below the largest one of Greater, and those of Greater above the smallest
+// one of Less
+func (in Inequality) Prune(s *State) bool {
+	return in.thermometer().Prune(s)
+}
+
+// thermometer returns the inequality as a thermometer of two cells, the bulb being Less
+func (in Inequality) thermometer() Thermometer {
+	return Thermometer{Cells: []Cell{in.Less, in.Greater}}
+}
+
+// rules returns the constraints of the grid followed by its inequalities and its clues
+func (sG *SudokuGrid) rules() []Constraint {
+	if len(sG.Inequalities) == 0 && len(sG.Clues) == 0 {
+		return sG.Constraints
+	}
+	rules := make([]Constraint, 0, len(sG.Constraints)+len(sG.Inequalities)+len(sG.Clues))
+	rules = append(rules, sG.Constraints...)
+	for _, in := range sG.Inequalities {
+		rules = append(rules, in)
+	}
+	for _, c := range sG.Clues {
+		rules = append(rules, c)
+	}
+	return rules
+}
+
+// validInequalities returns an error if an inequality does not join two adjacent cells of the grid
+func (sG *SudokuGrid) validInequalities() error {
+	for i, in := range sG.Inequalities {
+		if err := in.Valid(sG.Size); err != nil {
+			return fmt.Errorf("inequality %d (%s): %w", i+1, in, err)
+		}
+	}
+	return nil
+}
+
+// signs returns the signs of the inequalities to draw between the cells in row-major order: on the left side of
+// a cell, < or >, and above a cell, ^ if the cell above is the smaller one or v otherwise
+func (sG *SudokuGrid) signs() (left, above map[int]rune) {
+	left, above = map[int]rune{}, map[int]rune{}
+	for _, in := range sG.Inequalities {
+		a, b, sign := in.Less, in.Greater, '<'
+		if b.Row < a.Row || b.Col < a.Col {
+			a, b, sign = b, a, '>'
+		}
+		if a.Row == b.Row {
+			left[b.Row*sG.Size+b.Col] = sign
+		} else if sign == '<' {
+			above[b.Row*sG.Size+b.Col] = '^'
+		} else {
+			above[b.Row*sG.Size+b.Col] = 'v'
+		}
+	}
+	return left, above
+}
+
+// SetGridToInequalities turns the solved SudokuGrid into a comparison sudoku: the signs between all the adjacent
+// cells are set, then every value is removed. Values are given back until the puzzle has a unique solution, and
+// the inequalities which are not needed to keep it unique are hidden in a random order, until maxHideFailures of
+// them turn out to be needed. Grids larger than maxUnguidedSize are rejected with ErrInvalidDimensions.
+func (sG *SudokuGrid) SetGridToInequalities() error {
+	return sG.SetGridToInequalitiesContext(context.Background())
+}
+
+// SetGridToInequalitiesContext is like SetGridToInequalities but gives up with the context error once ctx is done,
+// in which case the SudokuGrid is left solved
+func (sG *SudokuGrid) SetGridToInequalitiesContext(ctx context.Context) error {
+	if len(sG.missingCells()) > 0 {
+		return errors.New("the grid must be solved to be turned into a comparison sudoku")
+	}
+	if sG.Size > maxUnguidedSize {
+		return fmt.Errorf("%w: comparison sudokus are generated up to %dx%d", ErrInvalidDimensions, maxUnguidedSize, maxUnguidedSize)
+	}
+	solution := sG.Clone()
+	fail := func(err error) error {
+		sG.Inequalities = nil
+		sG.copyFrom(solution)
+		return err
+	}
+
+	sG.Inequalities = nil
+	for x := 0; x < sG.Size; x++ {
+		for y := 0; y < sG.Size; y++ {
+			for _, n := range []Cell{{Row: x, Col: y + 1}, {Row: x + 1, Col: y}} {
+				if sG.isValidIndex(n.Row, n.Col) != nil {
+					continue
+				}
+				in := Inequality{Less: Cell{Row: x, Col: y}, Greater: n}
+				if sG.valueOf(in.Less) > sG.valueOf(in.Greater) {
+					in.Less, in.Greater = in.Greater, in.Less
+				}
+				sG.Inequalities = append(sG.Inequalities, in)
+			}
+		}
+	}
+	for _, c := range sG.filledCells() {
+		sG.Set(c.x, c.y, EMPTY_CELL)
+	}
+
+	// give back the value of a cell taking different values in two solutions, or of any empty cell if the
+	// solver cannot tell within its budget
+	for {
+		solutions, complete, err := sG.solutionsWithin(ctx)
+		if err != nil {
+			return fail(err)
+		}
+		if complete && len(solutions) < 2 {
+			break
+		}
+		var differ []Cell
+		if len(solutions) == 2 {
+			for i := range solutions[0].Grid {
+				for j := range solutions[0].Grid[i] {
+					if solutions[0].Grid[i][j] != solutions[1].Grid[i][j] {
+						differ = append(differ, Cell{Row: i, Col: j})
+					}
+				}
+			}
+		} else {
+			for _, c := range sG.missingCells() {
+				differ = append(differ, Cell{Row: c.x, Col: c.y})
+			}
+		}
+		c := differ[sG.random().Intn(len(differ))]
+		sG.Set(c.Row, c.Col, solution.Grid[c.Row][c.Col])
+	}
+
+	all := sG.Inequalities
+	hidden := make([]bool, len(all))
+	shown := func() []Inequality {
+		var res []Inequality
+		for i, in := range all {
+			if !hidden[i] {
+				res = append(res, in)
+			}
+		}
+		return res
+	}
+	failures := 0
+	for _, i := range sG.random().Perm(len(all)) {
+		if failures == maxHideFailures {
+			break
+		}
+		hidden[i] = true
+		sG.Inequalities = shown()
+		unique, err := sG.uniqueWithin(ctx)
+		if err != nil {
+			return fail(err)
+		}
+		if !unique {
+			// the puzzle became ambiguous, or too hard to prove unique, show the sign again
+			hidden[i] = false
+			failures++
+		}
+	}
+	sG.Inequalities = shown()
+	return nil
+}
+
+// valueOf returns the position of the value of the cell among the symbols of the grid, -1 if the cell is empty
+func (sG *SudokuGrid) valueOf(c Cell) int {
+	for v, symbol := range sG.symbols() {
+		if symbol == sG.Grid[c.Row][c.Col] {
+			return v
+		}
+	}
+	return -1
+}
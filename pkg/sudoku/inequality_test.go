@@ -0,0 +1,165 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// inequalityGrid returns the 4x4 pattern grid with a sign between r1c1 and r1c2 and one between r1c2 and r2c2
+func inequalityGrid() *SudokuGrid {
+	sG := patternGrid(4, 2, 2)
+	sG.Inequalities = []Inequality{
+		{Less: Cell{0, 0}, Greater: Cell{0, 1}},
+		{Less: Cell{0, 1}, Greater: Cell{1, 1}},
+	}
+	return sG
+}
+
+var _ = Describe("Inequality", func() {
+	DescribeTable("generates comparison puzzles with a unique solution",
+		func(size, partitionWidth, partitionHeight int) {
+			sG, err := GenerateSudokuGrid(size, partitionWidth, partitionHeight)
+			Expect(err).To(BeNil())
+			solution := sG.Clone()
+			Expect(sG.SetGridToInequalities()).To(Succeed())
+
+			Expect(sG.Inequalities).NotTo(BeEmpty())
+			Expect(len(sG.filledCells())).To(BeNumerically("<", size*size))
+			Expect(sG.Validate()).To(Succeed())
+			for _, in := range sG.Inequalities {
+				Expect(solution.valueOf(in.Less)).To(BeNumerically("<", solution.valueOf(in.Greater)))
+			}
+			Expect(sG.IsUnique()).To(BeTrue())
+
+			for _, name := range Solvers() {
+				s, err := GetSolver(name)
+				Expect(err).To(BeNil())
+				puzzle := sG.Clone()
+				_, err = puzzle.SolveWith(context.Background(), s)
+				Expect(err).To(BeNil())
+				Expect(puzzle.Grid).To(Equal(solution.Grid))
+			}
+		},
+		Entry("4x4", 4, 2, 2),
+		Entry("6x6", 6, 3, 2),
+	)
+
+	It("needs a solved grid", func() {
+		sG := inequalityGrid()
+		sG.Set(0, 0, EMPTY_CELL)
+		Expect(sG.SetGridToInequalities()).NotTo(Succeed())
+	})
+
+	It("rejects grids too large to be generated in time", func() {
+		sG, err := GenerateSudokuGrid(12, 4, 3)
+		Expect(err).To(BeNil())
+		solution := sG.Clone()
+		Expect(sG.SetGridToInequalities()).To(MatchError(ErrInvalidDimensions))
+		Expect(sG.Grid).To(Equal(solution.Grid))
+		Expect(sG.Inequalities).To(BeEmpty())
+	})
+
+	It("leaves the grid solved once the context is done", func() {
+		sG, err := GenerateSudokuGrid(6, 3, 2)
+		Expect(err).To(BeNil())
+		solution := sG.Clone()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(sG.SetGridToInequalitiesContext(ctx)).To(MatchError(ErrTimeout))
+		Expect(sG.Grid).To(Equal(solution.Grid))
+		Expect(sG.Inequalities).To(BeEmpty())
+	})
+
+	It("allows the values keeping the smaller cell below the greater one", func() {
+		sG := inequalityGrid()
+		sG.Set(0, 0, EMPTY_CELL)
+		s, _ := sG.state()
+		in := sG.Inequalities[0]
+		// r1c2 holds 2
+		Expect(in.Allows(s, Cell{0, 0}, 0)).To(BeTrue())
+		Expect(in.Allows(s, Cell{0, 0}, 1)).To(BeFalse())
+		Expect(in.Allows(s, Cell{0, 0}, 3)).To(BeFalse())
+		// the greater cell leaves room for a smaller value in the empty cell
+		Expect(in.Allows(s, Cell{0, 1}, 0)).To(BeFalse())
+		Expect(in.Allows(s, Cell{0, 1}, 3)).To(BeTrue())
+	})
+
+	It("reports the values breaking a sign", func() {
+		sG := inequalityGrid()
+		Expect(sG.Validate()).To(Succeed())
+
+		sG.Inequalities = append(sG.Inequalities, Inequality{Less: Cell{1, 0}, Greater: Cell{0, 0}})
+		err := sG.Validate()
+		Expect(err).To(MatchError(ErrConflict))
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Violations).To(Equal([]Violation{
+			{Candidate: Candidate{Cell: Cell{Row: 0, Col: 0}, Value: '1'}, Constraint: 2, Type: "inequality"},
+			{Candidate: Candidate{Cell: Cell{Row: 1, Col: 0}, Value: '3'}, Constraint: 2, Type: "inequality"},
+		}))
+	})
+
+	It("solves a puzzle given by its signs", func() {
+		sG := inequalityGrid()
+		solution := sG.Clone()
+		sG.Set(0, 0, EMPTY_CELL)
+		sG.Set(0, 1, EMPTY_CELL)
+		sG.Set(1, 1, EMPTY_CELL)
+		sG.Set(1, 0, EMPTY_CELL)
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			puzzle := sG.Clone()
+			_, err = puzzle.SolveWith(context.Background(), s)
+			Expect(err).To(BeNil())
+			Expect(puzzle.Grid).To(Equal(solution.Grid))
+		}
+	})
+
+	DescribeTable("rejects invalid inequalities",
+		func(in Inequality, expected error) {
+			sG, err := New(4, 2, 2)
+			Expect(err).To(BeNil())
+			sG.Inequalities = []Inequality{in}
+			Expect(sG.Valid()).To(MatchError(expected))
+		},
+		Entry("outside of the grid", Inequality{Less: Cell{3, 3}, Greater: Cell{3, 4}}, ErrOutOfBounds),
+		Entry("between diagonal cells", Inequality{Less: Cell{0, 0}, Greater: Cell{1, 1}}, ErrInvalidConstraint),
+		Entry("between distant cells", Inequality{Less: Cell{0, 0}, Greater: Cell{0, 2}}, ErrInvalidConstraint),
+		Entry("on a single cell", Inequality{Less: Cell{0, 0}, Greater: Cell{0, 0}}, ErrInvalidConstraint),
+	)
+
+	It("draws the signs between the cells", func() {
+		sG := inequalityGrid()
+		sG.Inequalities = append(sG.Inequalities, Inequality{Less: Cell{3, 1}, Greater: Cell{3, 0}})
+		sG.Set(1, 1, EMPTY_CELL)
+		Expect(sG.ToStringPrettify()).To(Equal("" +
+			"+---+---+---+---+\n" +
+			"|  1<  2|  3   4|\n" +
+			"+      ^+       +\n" +
+			"|  3   .|  1   2|\n" +
+			"+---+---+---+---+\n" +
+			"|  2   3|  4   1|\n" +
+			"+       +       +\n" +
+			"|  4>  1|  2   3|\n" +
+			"+---+---+---+---+\n"))
+	})
+
+	It("serializes and copies the inequalities", func() {
+		sG := inequalityGrid()
+		b, err := json.Marshal(sG)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(ContainSubstring(`"inequalities":[{"less":{"row":0,"col":0},"greater":{"row":0,"col":1}},`))
+		parsed := &SudokuGrid{}
+		Expect(json.Unmarshal(b, parsed)).To(Succeed())
+		Expect(parsed.Inequalities).To(Equal(sG.Inequalities))
+
+		clone := sG.Clone()
+		clone.Inequalities[0].Less = Cell{1, 0}
+		Expect(sG.Inequalities[0].Less).To(Equal(Cell{0, 0}))
+	})
+})
@@ -0,0 +1,222 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	// regionSwaps is the number of swaps per cell tried to shuffle the rows into irregular regions, see randomRegions
+	regionSwaps = 100
+	// maxJigsawSize is the size of the largest jigsaw sudokus made by the generator, the solver seldom completes
+	// the irregular regions of larger grids within the budget of a request
+	maxJigsawSize = 16
+)
+
+// NewJigsaw returns an empty size x size SudokuGrid whose subgrids are the irregular regions given by their id
+// for each cell, see SudokuGrid.Regions. The values of the cells are the given symbols, DefaultSymbols if empty.
+func NewJigsaw(size int, regions [][]int, symbols string) (*SudokuGrid, error) {
+	if err := validSize(size); err != nil {
+		return nil, err
+	}
+	sG := SudokuGrid{
+		Size:    size,
+		Regions: regions,
+		Symbols: customSymbols(symbols, size),
+	}
+
+	sG.Grid = make([][]rune, sG.Size)
+	for i := 0; i < sG.Size; i++ {
+		sG.Grid[i] = make([]rune, sG.Size)
+		for j := 0; j < len(sG.Grid[i]); j++ {
+			sG.Grid[i][j] = EMPTY_CELL
+		}
+	}
+	if err := sG.Valid(); err != nil {
+		return nil, err
+	}
+
+	sG.initMetadata()
+	return &sG, nil
+}
+
+// WithJigsaw generates grids whose subgrids are random connected regions instead of rectangles,
+// the partition dimensions are then ignored
+func WithJigsaw() GeneratorOption {
+	return func(o *generatorOptions) {
+		o.jigsaw = true
+	}
+}
+
+// validRegions returns an error if the regions are set but are not a Size x Size matrix of ids between 0 and
+// Size-1, each of them given to Size connected cells
+func (sG *SudokuGrid) validRegions() error {
+	if sG.Regions == nil {
+		return nil
+	}
+	if len(sG.Regions) != sG.Size {
+		return fmt.Errorf("%w: the given regions size does not match the given size property", ErrInvalidDimensions)
+	}
+
+	cells := make([]int, sG.Size)
+	for i := range sG.Regions {
+		if len(sG.Regions[i]) != sG.Size {
+			return fmt.Errorf("%w: the regions row %d size does not match the given size property", ErrInvalidDimensions, i+1)
+		}
+		for j, id := range sG.Regions[i] {
+			if id < 0 || id >= sG.Size {
+				return fmt.Errorf("%w: r%dc%d belongs to region %d, must be between 0 and %d", ErrInvalidRegion, i+1, j+1, id, sG.Size-1)
+			}
+			cells[id]++
+		}
+	}
+	for id, n := range cells {
+		if n != sG.Size {
+			return fmt.Errorf("%w: region %d has %d cells, must have %d", ErrInvalidRegion, id, n, sG.Size)
+		}
+		if !sG.connected(sG.Regions, id) {
+			return fmt.Errorf("%w: region %d is not connected", ErrInvalidRegion, id)
+		}
+	}
+	return nil
+}
+
+// connected returns true if the cells of the region id can all be reached from one another moving horizontally or
+// vertically within the region, the region has Size cells
+func (sG *SudokuGrid) connected(regions [][]int, id int) bool {
+	var part []Cell
+	seen := make([]bool, sG.Size*sG.Size)
+	for i := 0; i < sG.Size && len(part) == 0; i++ {
+		for j := 0; j < sG.Size; j++ {
+			if regions[i][j] == id {
+				part = append(part, Cell{Row: i, Col: j})
+				seen[i*sG.Size+j] = true
+				break
+			}
+		}
+	}
+	for k := 0; k < len(part); k++ {
+		for _, n := range sG.neighbours(part[k]) {
+			if regions[n.Row][n.Col] == id && !seen[n.Row*sG.Size+n.Col] {
+				seen[n.Row*sG.Size+n.Col] = true
+				part = append(part, n)
+			}
+		}
+	}
+	return len(part) == sG.Size
+}
+
+// generateJigsaw returns a solved jigsaw SudokuGrid: random regions are drawn, one of them is filled at random and
+// the solver completes the rest. Many layouts have no solution, the attempts are given up after
+// generationRestartNodes search nodes and retried with regions closer to the rows, which always have one.
+// Grids larger than maxJigsawSize are rejected with ErrInvalidDimensions.
+func generateJigsaw(ctx context.Context, size int, o *generatorOptions) (*SudokuGrid, error) {
+	if err := validSize(size); err != nil {
+		return nil, err
+	}
+	if size > maxJigsawSize {
+		return nil, fmt.Errorf("%w: jigsaw sudokus are generated up to %dx%d", ErrInvalidDimensions, maxJigsawSize, maxJigsawSize)
+	}
+	r := (&SudokuGrid{rng: o.rand}).random()
+	for attempt := 0; attempt <= generationRestarts; attempt++ {
+		swaps := regionSwaps * size * size >> uint(2*attempt)
+		sG, err := NewJigsaw(size, (&SudokuGrid{Size: size, rng: r}).randomRegions(swaps), o.symbols)
+		if err != nil {
+			return nil, err
+		}
+		sG.Variant = o.variant
+		sG.Constraints = o.constraints
+		sG.Seed, sG.rng = o.seed, r
+		if err := sG.Valid(); err != nil {
+			return nil, err
+		}
+		sG.random().Shuffle(len(sG.allowedValues), func(i, j int) { sG.allowedValues[i], sG.allowedValues[j] = sG.allowedValues[j], sG.allowedValues[i] })
+
+		maxNodes := generationRestartNodes
+		if attempt == generationRestarts {
+			maxNodes = 0
+		}
+		// the constraints may rule out the random region, the solver then starts from the empty grid
+		regions := 1
+		if len(sG.Constraints) > 0 && attempt == generationRestarts {
+			regions = 0
+		}
+		err = ErrNoSolution
+		if fillDiagonalSubgrids(sG, regions) {
+			_, err = sG.SolveWith(ctx, BacktrackingSolver{maxNodes: maxNodes})
+		}
+		if err == nil {
+			return sG, nil
+		}
+		if err := contextError(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return nil, errors.New("could not generate a valid sudoku grid")
+}
+
+// randomRegions returns random connected regions of Size cells: starting from the rows, a cell is swapped with a cell
+// of a neighbouring region as long as both regions stay connected, up to the given number of times
+func (sG *SudokuGrid) randomRegions(swaps int) [][]int {
+	n := sG.Size
+	regions := make([][]int, n)
+	for i := range regions {
+		regions[i] = make([]int, n)
+		for j := range regions[i] {
+			regions[i][j] = i
+		}
+	}
+
+	for swap := 0; swap < swaps; swap++ {
+		a := Cell{Row: sG.random().Intn(n), Col: sG.random().Intn(n)}
+		var others []Cell
+		for _, c := range sG.neighbours(a) {
+			if regions[c.Row][c.Col] != regions[a.Row][a.Col] {
+				others = append(others, c)
+			}
+		}
+		if len(others) == 0 {
+			continue
+		}
+		o := others[sG.random().Intn(len(others))]
+		from, to := regions[a.Row][a.Col], regions[o.Row][o.Col]
+
+		// a cell of the other region next to the region of a takes its place
+		var candidates []Cell
+		for i := range regions {
+			for j := range regions[i] {
+				if regions[i][j] != to {
+					continue
+				}
+				for _, c := range sG.neighbours(Cell{Row: i, Col: j}) {
+					if regions[c.Row][c.Col] == from && c != a {
+						candidates = append(candidates, Cell{Row: i, Col: j})
+						break
+					}
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		b := candidates[sG.random().Intn(len(candidates))]
+
+		regions[a.Row][a.Col], regions[b.Row][b.Col] = to, from
+		if !sG.connected(regions, from) || !sG.connected(regions, to) {
+			regions[a.Row][a.Col], regions[b.Row][b.Col] = from, to
+		}
+	}
+	return regions
+}
+
+// toStringJigsaw draws the SudokuGrid cell by cell with the outlines of its regions
+func (sG *SudokuGrid) toStringJigsaw() string {
+	group := make([]int, sG.Size*sG.Size)
+	for i := range sG.Regions {
+		for j, id := range sG.Regions[i] {
+			group[i*sG.Size+j] = id
+		}
+	}
+	return sG.toStringOutlined(group, nil, nil)
+}
@@ -0,0 +1,162 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// jigsawRegions is a 4x4 layout of irregular regions with 24 solutions
+var jigsawRegions = [][]int{
+	{2, 3, 3, 3},
+	{2, 2, 0, 3},
+	{1, 2, 0, 0},
+	{1, 1, 1, 0},
+}
+
+var _ = Describe("Jigsaw", func() {
+	DescribeTable("generates grids whose regions hold every symbol once",
+		func(size int) {
+			sG, err := GenerateSudokuGrid(size, 0, 0, WithJigsaw())
+			Expect(err).To(BeNil())
+			Expect(sG.Regions).To(HaveLen(size))
+			Expect(sG.PartitionWidth).To(BeZero())
+			Expect(sG.Valid()).To(Succeed())
+			Expect(isSolved(sG)).To(BeTrue())
+		},
+		Entry("5x5", 5),
+		Entry("7x7", 7),
+		Entry("9x9", 9),
+		Entry("12x12", 12),
+	)
+
+	It("draws connected regions of equal size", func() {
+		for _, size := range []int{4, 6, 9, 16} {
+			sG := &SudokuGrid{Size: size}
+			sG.Regions = sG.randomRegions(regionSwaps * size * size)
+			Expect(sG.validRegions()).To(Succeed())
+		}
+	})
+
+	It("generates puzzles whose unique solution follows the regions", func() {
+		sG, err := GenerateSudokuGrid(9, 0, 0, WithJigsaw())
+		Expect(err).To(BeNil())
+		solution := sG.Clone()
+		Expect(sG.SetGridToLevel("medium", WithGradedDifficulty(DefaultGradingAttempts))).To(Succeed())
+		Expect(sG.IsUnique()).To(BeTrue())
+
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			puzzle := sG.Clone()
+			_, err = puzzle.SolveWith(context.Background(), s)
+			Expect(err).To(BeNil())
+			Expect(puzzle.Grid).To(Equal(solution.Grid))
+		}
+	})
+
+	It("takes the regions into account when counting the solutions", func() {
+		sG, err := NewJigsaw(4, jigsawRegions, "")
+		Expect(err).To(BeNil())
+		Expect(sG.CountSolutions(0)).To(Equal(24))
+		for _, solution := range sG.FindSolutions(0) {
+			Expect(isSolved(solution)).To(BeTrue())
+		}
+
+		// some layouts have no solution at all
+		sG, err = NewJigsaw(4, [][]int{{0, 0, 0, 1}, {0, 2, 1, 1}, {2, 2, 3, 1}, {2, 3, 3, 3}}, "")
+		Expect(err).To(BeNil())
+		_, err = sG.SolveWith(context.Background(), BacktrackingSolver{})
+		Expect(err).To(MatchError(ErrNoSolution))
+	})
+
+	It("reports the values repeated in a region", func() {
+		sG, err := NewJigsaw(4, jigsawRegions, "")
+		Expect(err).To(BeNil())
+		sG.Set(1, 0, '1')
+		sG.Set(2, 1, '1')
+
+		var verr *ValidationError
+		Expect(errors.As(sG.Validate(), &verr)).To(BeTrue())
+		Expect(verr.Conflicts).To(Equal([]Conflict{
+			{Candidate: Candidate{Cell: Cell{Row: 1, Col: 0}, Value: '1'}, Unit: "box", Index: 2},
+			{Candidate: Candidate{Cell: Cell{Row: 2, Col: 1}, Value: '1'}, Unit: "box", Index: 2},
+		}))
+	})
+
+	DescribeTable("rejects invalid regions",
+		func(regions [][]int, expected error) {
+			_, err := NewJigsaw(4, regions, "")
+			Expect(err).To(MatchError(expected))
+		},
+		Entry("missing a row", jigsawRegions[1:], ErrInvalidDimensions),
+		Entry("with an unknown region", [][]int{{2, 3, 3, 3}, {2, 2, 0, 3}, {1, 2, 0, 0}, {1, 1, 1, 4}}, ErrInvalidRegion),
+		Entry("with regions of different sizes", [][]int{{2, 3, 3, 3}, {2, 2, 0, 3}, {1, 2, 0, 0}, {1, 1, 1, 1}}, ErrInvalidRegion),
+		Entry("with a disconnected region", [][]int{{2, 3, 3, 3}, {2, 2, 0, 3}, {1, 2, 0, 1}, {1, 0, 1, 0}}, ErrInvalidRegion),
+	)
+
+	It("requires the partitions of grids without regions", func() {
+		_, err := New(4, 0, 0)
+		Expect(err).To(MatchError(ErrInvalidDimensions))
+
+		// the partitions of a jigsaw sudoku are optional but must be valid if set
+		sG, err := NewJigsaw(4, jigsawRegions, "")
+		Expect(err).To(BeNil())
+		sG.PartitionWidth = 3
+		Expect(sG.Valid()).To(MatchError(ErrInvalidDimensions))
+		sG.PartitionWidth, sG.PartitionHeight = 2, 2
+		Expect(sG.Valid()).To(Succeed())
+		Expect(sG.GetSubgridIndex(0, 1)).To(Equal(3))
+	})
+
+	It("rejects sizes that are not positive", func() {
+		for _, size := range []int{0, -4} {
+			_, err := NewJigsaw(size, nil, "")
+			Expect(err).To(MatchError(ErrInvalidDimensions))
+			_, err = GenerateSudokuGrid(size, 0, 0, WithJigsaw())
+			Expect(err).To(MatchError(ErrInvalidDimensions))
+		}
+	})
+
+	It("rejects jigsaw grids larger than 16x16", func() {
+		_, err := GenerateSudokuGrid(25, 5, 5, WithJigsaw())
+		Expect(err).To(MatchError(ErrInvalidDimensions))
+	})
+
+	It("draws the outlines of the regions", func() {
+		sG, err := NewJigsaw(4, jigsawRegions, "")
+		Expect(err).To(BeNil())
+		sG.Set(0, 0, '1')
+		sG.Set(1, 2, '3')
+		Expect(sG.ToStringPrettify()).To(Equal("" +
+			"+---+---+---+---+\n" +
+			"|  1|  .   .   .|\n" +
+			"+   +---+---+   +\n" +
+			"|  .   .|  3|  .|\n" +
+			"+---+   +   +---+\n" +
+			"|  .|  .|  .   .|\n" +
+			"+   +---+---+   +\n" +
+			"|  .   .   .|  .|\n" +
+			"+---+---+---+---+\n"))
+	})
+
+	It("serializes and copies the regions", func() {
+		sG, err := NewJigsaw(4, jigsawRegions, "")
+		Expect(err).To(BeNil())
+		b, err := json.Marshal(sG)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(ContainSubstring(`"regions":[[2,3,3,3],[2,2,0,3],[1,2,0,0],[1,1,1,0]]`))
+
+		parsed := &SudokuGrid{}
+		Expect(json.Unmarshal(b, parsed)).To(Succeed())
+		Expect(parsed.Regions).To(Equal(jigsawRegions))
+		Expect(parsed.CountSolutions(0)).To(Equal(24))
+
+		clone := sG.Clone()
+		clone.Regions[0][0] = 3
+		Expect(sG.Regions[0][0]).To(Equal(2))
+	})
+})
@@ -0,0 +1,257 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Cage is a group of cells of a Killer Sudoku holding distinct values which add up to Sum.
+// The value of a symbol is its position in the symbols of the grid, starting from 1: the digit itself for the
+// default symbols.
+type Cage struct {
+	Sum   int    `json:"sum"`
+	Cells []Cell `json:"cells"`
+}
+
+const (
+	// maxCageSize is the largest number of cells of the cages made by SetGridToKiller
+	maxCageSize = 4
+	// maxKillerSize is the size of the largest grids SetGridToKiller turns into killer sudokus, larger ones need
+	// hundreds of uniqueness checks taking their whole budget
+	maxKillerSize = 16
+)
+
+// validCages returns an error if a cage is empty, larger than the size of the grid, has a cell outside of the grid
+// or in another cage, or a sum its cells cannot add up to
+func (sG *SudokuGrid) validCages() error {
+	seen := make(map[Cell]int)
+	for i, cage := range sG.Cages {
+		k := len(cage.Cells)
+		if k == 0 || k > sG.Size {
+			return fmt.Errorf("%w: cage %d has %d cells, must be between 1 and %d", ErrInvalidCage, i+1, k, sG.Size)
+		}
+		for _, c := range cage.Cells {
+			if err := sG.isValidIndex(c.Row, c.Col); err != nil {
+				return err
+			}
+			if other, ok := seen[c]; ok {
+				return fmt.Errorf("%w: r%dc%d belongs to cages %d and %d", ErrInvalidCage, c.Row+1, c.Col+1, other+1, i+1)
+			}
+			seen[c] = i
+		}
+		if low, high := k*(k+1)/2, k*(2*sG.Size-k+1)/2; cage.Sum < low || cage.Sum > high {
+			return fmt.Errorf("%w: cage %d of %d cells cannot add up to %d", ErrInvalidCage, i+1, k, cage.Sum)
+		}
+	}
+	return nil
+}
+
+// CageSum is a cage whose values exceed its sum, or do not add up to it once all its cells are filled
+type CageSum struct {
+	Cage int `json:"cage"` // index of the cage in the cages of the grid, starting from 0
+	Sum  int `json:"sum"`  // sum of the values of the filled cells of the cage
+}
+
+// cageSums returns the cages whose filled cells break their sum
+func (sG *SudokuGrid) cageSums() []CageSum {
+	value := make(map[rune]int, sG.Size)
+	for v, symbol := range sG.symbols() {
+		value[symbol] = v + 1
+	}
+
+	var res []CageSum
+	for i, cage := range sG.Cages {
+		sum, full := 0, true
+		for _, c := range cage.Cells {
+			if v := sG.Grid[c.Row][c.Col]; v != EMPTY_CELL {
+				sum += value[v]
+			} else {
+				full = false
+			}
+		}
+		if sum > cage.Sum || (full && sum != cage.Sum) {
+			res = append(res, CageSum{Cage: i, Sum: sum})
+		}
+	}
+	return res
+}
+
+// SetGridToKiller turns the solved SudokuGrid into a Killer Sudoku: its cells are partitioned into cages of up to
+// 4 cells holding the sum of their values, then every value is removed. The cages are split until the puzzle is
+// proven to have a unique solution within uniquenessCheckNodes search nodes, which keeps large grids from taking
+// forever at the cost of smaller cages. Grids larger than maxKillerSize are rejected with ErrInvalidDimensions.
+func (sG *SudokuGrid) SetGridToKiller() error {
+	return sG.SetGridToKillerContext(context.Background())
+}
+
+// SetGridToKillerContext is like SetGridToKiller but gives up with the context error once ctx is done,
+// in which case the SudokuGrid is left solved
+func (sG *SudokuGrid) SetGridToKillerContext(ctx context.Context) error {
+	if len(sG.missingCells()) > 0 {
+		return errors.New("the grid must be solved to be turned into a killer sudoku")
+	}
+	if sG.Size > maxKillerSize {
+		return fmt.Errorf("%w: killer sudokus are generated up to %dx%d", ErrInvalidDimensions, maxKillerSize, maxKillerSize)
+	}
+	solution := sG.Clone()
+
+	sG.Cages = randomCages(sG)
+	for _, c := range sG.filledCells() {
+		sG.Set(c.x, c.y, EMPTY_CELL)
+	}
+
+	// split the cage of a cell taking different values in two solutions, or of any cell of a cage of several cells
+	// if the solver cannot tell within its budget, down to single cells whose sums are their values if need be
+	for {
+		solutions, complete, err := sG.solutionsWithin(ctx)
+		if err != nil {
+			sG.Cages = nil
+			sG.copyFrom(solution)
+			return err
+		}
+		if complete && len(solutions) < 2 {
+			return nil
+		}
+
+		var differ []Cell
+		if len(solutions) == 2 {
+			for i := range solutions[0].Grid {
+				for j := range solutions[0].Grid[i] {
+					if solutions[0].Grid[i][j] != solutions[1].Grid[i][j] {
+						differ = append(differ, Cell{Row: i, Col: j})
+					}
+				}
+			}
+		} else {
+			for _, cage := range sG.Cages {
+				if len(cage.Cells) > 1 {
+					differ = append(differ, cage.Cells...)
+				}
+			}
+		}
+		sG.splitCage(differ[sG.random().Intn(len(differ))], solution)
+	}
+}
+
+// randomCages partitions the cells of the solved SudokuGrid into connected cages of distinct values
+func randomCages(sG *SudokuGrid) []Cage {
+	caged := make([][]bool, sG.Size)
+	for i := range caged {
+		caged[i] = make([]bool, sG.Size)
+	}
+
+	var cages []Cage
+	for _, start := range sG.random().Perm(sG.Size * sG.Size) {
+		c := Cell{Row: start / sG.Size, Col: start % sG.Size}
+		if caged[c.Row][c.Col] {
+			continue
+		}
+		caged[c.Row][c.Col] = true
+		cells, values := []Cell{c}, map[rune]bool{sG.Grid[c.Row][c.Col]: true}
+
+		for size := 2 + sG.random().Intn(maxCageSize-1); len(cells) < size; {
+			var frontier []Cell
+			for _, cell := range cells {
+				for _, n := range sG.neighbours(cell) {
+					if !caged[n.Row][n.Col] && !values[sG.Grid[n.Row][n.Col]] {
+						frontier = append(frontier, n)
+					}
+				}
+			}
+			if len(frontier) == 0 {
+				break
+			}
+			n := frontier[sG.random().Intn(len(frontier))]
+			caged[n.Row][n.Col] = true
+			cells = append(cells, n)
+			values[sG.Grid[n.Row][n.Col]] = true
+		}
+		cages = append(cages, Cage{Cells: cells, Sum: sG.sumOf(cells)})
+	}
+	return cages
+}
+
+// splitCage takes the cell out of its cage, the rest of the cage is divided into its connected parts.
+// The sums are those of the cells in the solution.
+func (sG *SudokuGrid) splitCage(cell Cell, solution *SudokuGrid) {
+	for i, cage := range sG.Cages {
+		in := map[Cell]bool{}
+		for _, c := range cage.Cells {
+			in[c] = c != cell
+		}
+		if _, ok := in[cell]; !ok {
+			continue
+		}
+
+		parts := []Cage{{Cells: []Cell{cell}, Sum: solution.sumOf([]Cell{cell})}}
+		for _, c := range cage.Cells {
+			if !in[c] {
+				continue
+			}
+			// collect the part of c, marking its cells as done
+			part := []Cell{c}
+			in[c] = false
+			for k := 0; k < len(part); k++ {
+				for _, n := range sG.neighbours(part[k]) {
+					if in[n] {
+						in[n] = false
+						part = append(part, n)
+					}
+				}
+			}
+			parts = append(parts, Cage{Cells: part, Sum: solution.sumOf(part)})
+		}
+		sG.Cages = append(append(sG.Cages[:i:i], parts...), sG.Cages[i+1:]...)
+		return
+	}
+}
+
+// neighbours returns the cells next to the cell horizontally or vertically
+func (sG *SudokuGrid) neighbours(c Cell) []Cell {
+	var res []Cell
+	for _, d := range []Cell{{-1, 0}, {0, -1}, {0, 1}, {1, 0}} {
+		n := Cell{Row: c.Row + d.Row, Col: c.Col + d.Col}
+		if sG.isValidIndex(n.Row, n.Col) == nil {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// sumOf returns the sum of the values of the cells, see Cage
+func (sG *SudokuGrid) sumOf(cells []Cell) int {
+	sum := 0
+	for _, c := range cells {
+		for v, symbol := range sG.symbols() {
+			if symbol == sG.Grid[c.Row][c.Col] {
+				sum += v + 1
+			}
+		}
+	}
+	return sum
+}
+
+// toStringKiller draws the SudokuGrid cell by cell with the outlines of its cages, the sum of a cage is written
+// in its first cell in row-major order. The borders of the subgrids crossing a cage are dotted.
+func (sG *SudokuGrid) toStringKiller() string {
+	n := sG.Size
+	group, subgrids := make([]int, n*n), make([]int, n*n)
+	for cell := range group {
+		// a cell out of every cage is outlined on its own
+		group[cell] = -1 - cell
+		subgrids[cell] = sG.GetSubgridIndex(cell/n, cell%n)
+	}
+	labels := make(map[int]string)
+	for i, cage := range sG.Cages {
+		first := n * n
+		for _, c := range cage.Cells {
+			group[c.Row*n+c.Col] = i
+			if c.Row*n+c.Col < first {
+				first = c.Row*n + c.Col
+			}
+		}
+		labels[first] = fmt.Sprint(cage.Sum)
+	}
+	return sG.toStringOutlined(group, subgrids, labels)
+}
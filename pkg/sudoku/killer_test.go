@@ -0,0 +1,175 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// killerGrid returns the 4x4 pattern grid with a cage of 2 cells in the first row, one of 2 cells in the first
+// column and one of 4 cells in the bottom right box
+func killerGrid() *SudokuGrid {
+	sG := patternGrid(4, 2, 2)
+	sG.Cages = []Cage{
+		{Sum: 3, Cells: []Cell{{0, 0}, {0, 1}}},
+		{Sum: 5, Cells: []Cell{{1, 0}, {2, 0}}},
+		{Sum: 10, Cells: []Cell{{2, 2}, {2, 3}, {3, 3}, {3, 2}}},
+	}
+	return sG
+}
+
+var _ = Describe("Killer", func() {
+	DescribeTable("generates puzzles without givens whose cages have a unique solution",
+		func(size, partitionWidth, partitionHeight int) {
+			sG, err := GenerateSudokuGrid(size, partitionWidth, partitionHeight)
+			Expect(err).To(BeNil())
+			solution := sG.Clone()
+			Expect(sG.SetGridToKiller()).To(Succeed())
+
+			Expect(sG.filledCells()).To(BeEmpty())
+			Expect(sG.Validate()).To(Succeed())
+			cells := 0
+			for _, cage := range sG.Cages {
+				Expect(len(cage.Cells)).To(BeNumerically("<=", maxCageSize))
+				Expect(cage.Sum).To(Equal(solution.sumOf(cage.Cells)))
+				cells += len(cage.Cells)
+			}
+			Expect(cells).To(Equal(size * size))
+
+			for _, name := range Solvers() {
+				s, err := GetSolver(name)
+				Expect(err).To(BeNil())
+				n, _, err := sG.CountSolutionsWith(context.Background(), s, 0)
+				Expect(err).To(BeNil())
+				Expect(n).To(Equal(1))
+
+				puzzle := sG.Clone()
+				_, err = puzzle.SolveWith(context.Background(), s)
+				Expect(err).To(BeNil())
+				Expect(puzzle.Grid).To(Equal(solution.Grid))
+			}
+		},
+		Entry("4x4", 4, 2, 2),
+		Entry("6x6", 6, 3, 2),
+		Entry("9x9", 9, 3, 3),
+		Entry("12x12", 12, 4, 3),
+	)
+
+	It("requires a solved grid", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		Expect(sG.SetGridToKiller()).NotTo(Succeed())
+		Expect(sG.Cages).To(BeNil())
+	})
+
+	It("rejects grids too large to be generated in time", func() {
+		sG, err := GenerateSudokuGrid(25, 5, 5)
+		Expect(err).To(BeNil())
+		solution := sG.Clone()
+		Expect(sG.SetGridToKiller()).To(MatchError(ErrInvalidDimensions))
+		Expect(sG.Grid).To(Equal(solution.Grid))
+		Expect(sG.Cages).To(BeEmpty())
+	})
+
+	It("leaves the grid solved once the context is done", func() {
+		sG := patternGrid(9, 3, 3)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(sG.SetGridToKillerContext(ctx)).To(MatchError(ErrTimeout))
+		Expect(sG.Cages).To(BeNil())
+		Expect(sG.Grid).To(Equal(patternGrid(9, 3, 3).Grid))
+	})
+
+	It("reports the cages breaking their sum or holding the same value twice", func() {
+		sG := killerGrid()
+		Expect(sG.Validate()).To(Succeed())
+
+		// the first cage adds up to 4, the second one holds 3 twice and adds up to 6
+		sG.Set(0, 1, '3')
+		sG.Set(2, 0, '3')
+		err := sG.Validate()
+		Expect(err).To(MatchError(ErrConflict))
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.CageSums).To(Equal([]CageSum{{Cage: 0, Sum: 4}, {Cage: 1, Sum: 6}}))
+		Expect(verr.Conflicts).To(ContainElement(Conflict{
+			Candidate: Candidate{Cell: Cell{Row: 2, Col: 0}, Value: '3'}, Unit: "cage", Index: 1,
+		}))
+		Expect(err.Error()).To(ContainSubstring("the values of cage 1 add up to 4"))
+	})
+
+	It("reports a sum exceeded before the cage is full", func() {
+		sG := killerGrid()
+		sG.Cages[1].Sum = 3
+		sG.Set(1, 0, '4')
+		sG.Set(2, 0, EMPTY_CELL)
+		var verr *ValidationError
+		Expect(errors.As(sG.Validate(), &verr)).To(BeTrue())
+		Expect(verr.CageSums).To(Equal([]CageSum{{Cage: 1, Sum: 4}}))
+	})
+
+	DescribeTable("rejects invalid cages",
+		func(cage Cage, expected error) {
+			sG := killerGrid()
+			sG.Cages = append(sG.Cages, cage)
+			Expect(sG.Valid()).To(MatchError(expected))
+		},
+		Entry("empty", Cage{Sum: 1}, ErrInvalidCage),
+		Entry("overlapping another cage", Cage{Sum: 3, Cells: []Cell{{0, 1}, {0, 2}}}, ErrInvalidCage),
+		Entry("outside of the grid", Cage{Sum: 3, Cells: []Cell{{0, 2}, {0, 4}}}, ErrOutOfBounds),
+		Entry("with a sum too small", Cage{Sum: 2, Cells: []Cell{{0, 2}, {0, 3}}}, ErrInvalidCage),
+		Entry("with a sum too large", Cage{Sum: 8, Cells: []Cell{{0, 2}, {0, 3}}}, ErrInvalidCage),
+	)
+
+	It("takes the cages into account when solving", func() {
+		// without cages, the empty 4x4 grid has 288 solutions
+		sG, err := New(4, 2, 2)
+		Expect(err).To(BeNil())
+		sG.Cages = killerGrid().Cages
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			solutions, _, err := sG.FindSolutionsWith(context.Background(), s, 0)
+			Expect(err).To(BeNil())
+			Expect(solutions).NotTo(BeEmpty())
+			Expect(len(solutions)).To(BeNumerically("<", 288))
+			for _, solution := range solutions {
+				Expect(solution.Validate()).To(Succeed())
+			}
+		}
+	})
+
+	It("draws the outlines and the sums of the cages, and the borders of the subgrids crossing them", func() {
+		sG := killerGrid()
+		sG.Cages = append(sG.Cages, Cage{Sum: 5, Cells: []Cell{{1, 1}, {1, 2}}})
+		sG.Set(0, 1, EMPTY_CELL)
+		Expect(sG.ToStringPrettify()).To(Equal("" +
+			"+----+----+----+----+\n" +
+			"|3  1    .|   3|   4|\n" +
+			"+----+----+----+----+\n" +
+			"|5  3|5  4:   1|   2|\n" +
+			"+....+----+----+----+\n" +
+			"|   2|   3|10 4    1|\n" +
+			"+----+----+         +\n" +
+			"|   4|   1|   2    3|\n" +
+			"+----+----+----+----+\n"))
+	})
+
+	It("serializes and copies the cages", func() {
+		sG := killerGrid()
+		b, err := json.Marshal(sG)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(ContainSubstring(`"cages":[{"sum":3,"cells":[{"row":0,"col":0},{"row":0,"col":1}]}`))
+
+		parsed := &SudokuGrid{}
+		Expect(json.Unmarshal(b, parsed)).To(Succeed())
+		Expect(parsed.Cages).To(Equal(sG.Cages))
+		Expect(parsed.Validate()).To(Succeed())
+
+		clone := sG.Clone()
+		clone.Cages[0].Cells[0] = Cell{Row: 3, Col: 3}
+		Expect(sG.Cages[0].Cells[0]).To(Equal(Cell{Row: 0, Col: 0}))
+	})
+})
@@ -0,0 +1,777 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Technique is a deduction rule used by people to make progress on a puzzle
+type Technique string
+
+const (
+	NakedSingle      Technique = "naked single"
+	HiddenSingle     Technique = "hidden single"
+	NakedPair        Technique = "naked pair"
+	NakedTriple      Technique = "naked triple"
+	HiddenPair       Technique = "hidden pair"
+	HiddenTriple     Technique = "hidden triple"
+	PointingPair     Technique = "pointing pair"
+	BoxLineReduction Technique = "box-line reduction"
+	XWing            Technique = "x-wing"
+	SimpleColouring  Technique = "simple colouring"
+	XYWing           Technique = "xy-wing"
+	Swordfish        Technique = "swordfish"
+)
+
+// techniques lists the techniques known by the logical solver in the order they are tried, by increasing weight so
+// that every step uses the easiest technique available. The weight of a technique measures how hard it is to spot,
+// it is used to grade puzzles.
+var techniques = []struct {
+	name   Technique
+	weight int
+	apply  func(lg *logic) *Step
+}{
+	{NakedSingle, 1, (*logic).nakedSingle},
+	{HiddenSingle, 5, (*logic).hiddenSingle},
+	{NakedPair, 20, func(lg *logic) *Step { return lg.nakedSubset(2, NakedPair) }},
+	{PointingPair, 20, (*logic).pointingPair},
+	{HiddenPair, 22, func(lg *logic) *Step { return lg.hiddenSubset(2, HiddenPair) }},
+	{BoxLineReduction, 22, (*logic).boxLineReduction},
+	{NakedTriple, 24, func(lg *logic) *Step { return lg.nakedSubset(3, NakedTriple) }},
+	{HiddenTriple, 26, func(lg *logic) *Step { return lg.hiddenSubset(3, HiddenTriple) }},
+	{XWing, 40, func(lg *logic) *Step { return lg.fish(2, XWing) }},
+	{SimpleColouring, 45, (*logic).simpleColouring},
+	{XYWing, 60, (*logic).xyWing},
+	{Swordfish, 70, func(lg *logic) *Step { return lg.fish(3, Swordfish) }},
+}
+
+// Techniques returns the techniques known by the logical solver in the order they are tried
+func Techniques() []Technique {
+	names := make([]Technique, len(techniques))
+	for i, t := range techniques {
+		names[i] = t.name
+	}
+	return names
+}
+
+// Cell is the position of a cell in the grid, indexes start from 0
+type Cell struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// Candidate is a value in a cell, either placed or eliminated by a Step
+type Candidate struct {
+	Cell
+	Value rune `json:"value"`
+}
+
+// Step is a single deduction made by the logical solver
+type Step struct {
+	Technique    Technique   `json:"technique"`
+	Cells        []Cell      `json:"cells"` // cells the deduction is based on
+	Placements   []Candidate `json:"placements,omitempty"`
+	Eliminations []Candidate `json:"eliminations,omitempty"`
+	Description  string      `json:"description"`
+}
+
+// LogicalSolution is the outcome of solving a puzzle with human-style techniques
+type LogicalSolution struct {
+	Steps []Step `json:"steps"`
+	Stuck bool   `json:"stuck"` // true if no known technique makes progress before the grid is complete
+}
+
+// SolveLogically solves the SudokuGrid in-place the way a person would: at each step, the easiest technique
+// making progress is applied. It never guesses, if no technique applies the solution is reported as stuck
+// and the SudokuGrid holds the values deduced so far. An error is returned if the puzzle has no solution.
+func (sG *SudokuGrid) SolveLogically() (*LogicalSolution, error) {
+	return sG.SolveLogicallyContext(context.Background())
+}
+
+// SolveLogicallyContext is like SolveLogically but gives up with the context error once ctx is done, which is checked
+// between the steps, in which case the SudokuGrid is left unchanged
+func (sG *SudokuGrid) SolveLogicallyContext(ctx context.Context) (*LogicalSolution, error) {
+	lg, err := newLogic(sG)
+	if err != nil {
+		return nil, err
+	}
+
+	solution := &LogicalSolution{Steps: []Step{}}
+	for !lg.solved() {
+		if err := contextError(ctx); err != nil {
+			return nil, err
+		}
+		step, err := lg.next()
+		if err != nil {
+			return nil, err
+		}
+		if step == nil {
+			solution.Stuck = true
+			break
+		}
+		solution.Steps = append(solution.Steps, *step)
+	}
+	lg.apply(sG)
+	return solution, nil
+}
+
+// logic holds the pencil marks of a puzzle being solved by the logical solver.
+// Unlike board, nothing is deduced implicitly: every deduction is made by a technique and recorded as a Step.
+type logic struct {
+	*layout
+	values     []int    // value of each cell, -1 if the cell is empty
+	candidates []uint64 // candidates of each empty cell, the placed value for the other cells
+}
+
+func newLogic(sG *SudokuGrid) (*logic, error) {
+	if sG.Size > maxSymbols {
+		return nil, fmt.Errorf("%w: grids larger than %dx%d are not supported", ErrInvalidDimensions, maxSymbols, maxSymbols)
+	}
+	l := newLayout(sG)
+	n := l.cells
+	lg := &logic{
+		layout:     l,
+		values:     make([]int, n),
+		candidates: make([]uint64, n),
+	}
+
+	index := make(map[rune]int, len(l.symbols))
+	for v, symbol := range l.symbols {
+		index[symbol] = v
+	}
+	for cell := range lg.values {
+		lg.values[cell] = -1
+		symbol := sG.Grid[cell/l.size][cell%l.size]
+		if symbol == EMPTY_CELL {
+			continue
+		}
+		v, ok := index[symbol]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q in %s", ErrInvalidSymbol, symbol, lg.cellName(cell))
+		}
+		lg.values[cell] = v
+	}
+
+	all := uint64(1)<<uint(l.size) - 1
+	for cell, v := range lg.values {
+		if v != -1 {
+			lg.candidates[cell] = 1 << uint(v)
+			continue
+		}
+		lg.candidates[cell] = all
+		for _, peer := range lg.peers[cell] {
+			if lg.values[peer] != -1 {
+				lg.candidates[cell] &^= 1 << uint(lg.values[peer])
+			}
+		}
+	}
+	for cell, v := range lg.values {
+		for _, peer := range lg.peers[cell] {
+			if v != -1 && lg.values[peer] == v {
+				return nil, ErrNoSolution
+			}
+		}
+	}
+	if !lg.restrictAll(lg.values, lg.candidates, lg.drop) || lg.contradiction() {
+		return nil, ErrNoSolution
+	}
+	return lg, nil
+}
+
+// next applies the easiest technique making progress and returns its Step, nil if no technique applies
+func (lg *logic) next() (*Step, error) {
+	for _, t := range techniques {
+		step := t.apply(lg)
+		if step == nil {
+			continue
+		}
+		if lg.contradiction() {
+			return nil, ErrNoSolution
+		}
+		return step, nil
+	}
+	return nil, nil
+}
+
+func (lg *logic) solved() bool {
+	for _, v := range lg.values {
+		if v == -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// contradiction returns true if a cell has no candidate left, or a value has no place left in a unit.
+// A filled cell has none once a constraint rules its value out.
+func (lg *logic) contradiction() bool {
+	for cell := range lg.values {
+		if lg.candidates[cell] == 0 {
+			return true
+		}
+	}
+	for _, unit := range lg.units {
+		var seen uint64
+		for _, cell := range unit {
+			seen |= lg.candidates[cell]
+		}
+		if len(unit) == lg.size && bits.OnesCount64(seen) != lg.size {
+			return true
+		}
+	}
+	return false
+}
+
+// apply writes the values placed so far into the SudokuGrid
+func (lg *logic) apply(sG *SudokuGrid) {
+	for cell, v := range lg.values {
+		if v != -1 {
+			sG.Set(cell/lg.size, cell%lg.size, lg.symbols[v])
+		}
+	}
+}
+
+// place sets the value of the cell, removes it from the candidates of its peers and removes the values the
+// constraints do not allow anymore from the candidates of the cells they relate to it
+func (lg *logic) place(cell, v int) Candidate {
+	lg.values[cell] = v
+	lg.candidates[cell] = 1 << uint(v)
+	for _, peer := range lg.peers[cell] {
+		if lg.values[peer] == -1 {
+			lg.candidates[peer] &^= 1 << uint(v)
+		}
+	}
+	// a contradiction leaves a cell without candidate, it is reported by contradiction
+	lg.constrain(lg.values, lg.candidates, cell, lg.drop)
+	return lg.candidate(cell, v)
+}
+
+// drop removes v from the candidates of the cell, filled or not, returns false if none is left
+func (lg *logic) drop(cell, v int) bool {
+	lg.candidates[cell] &^= 1 << uint(v)
+	return lg.candidates[cell] != 0
+}
+
+// eliminate removes the values of mask from the candidates of the empty cell, and returns the removed candidates
+func (lg *logic) eliminate(cell int, mask uint64) []Candidate {
+	var removed []Candidate
+	if lg.values[cell] != -1 {
+		return removed
+	}
+	for m := lg.candidates[cell] & mask; m != 0; m &= m - 1 {
+		removed = append(removed, lg.candidate(cell, bits.TrailingZeros64(m)))
+	}
+	lg.candidates[cell] &^= mask
+	return removed
+}
+
+// positions returns the empty cells of the unit where v is still a candidate
+func (lg *logic) positions(u, v int) []int {
+	var cells []int
+	for _, cell := range lg.units[u] {
+		if lg.values[cell] == -1 && lg.candidates[cell]&(1<<uint(v)) != 0 {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// placed returns true if v is already placed in the unit
+func (lg *logic) placed(u, v int) bool {
+	for _, cell := range lg.units[u] {
+		if lg.values[cell] == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (lg *logic) candidate(cell, v int) Candidate {
+	return Candidate{Cell: lg.cell(cell), Value: lg.symbols[v]}
+}
+
+func (lg *logic) cell(cell int) Cell {
+	return Cell{Row: cell / lg.size, Col: cell % lg.size}
+}
+
+func (lg *logic) cellList(cells []int) []Cell {
+	list := make([]Cell, len(cells))
+	for i, cell := range cells {
+		list[i] = lg.cell(cell)
+	}
+	return list
+}
+
+// cellName returns the usual r<row>c<column> name of the cell, indexes start from 1
+func (lg *logic) cellName(cell int) string {
+	return fmt.Sprintf("r%dc%d", cell/lg.size+1, cell%lg.size+1)
+}
+
+func (lg *logic) cellNames(cells []int) string {
+	names := make([]string, len(cells))
+	for i, cell := range cells {
+		names[i] = lg.cellName(cell)
+	}
+	return strings.Join(names, ", ")
+}
+
+// valueNames returns the symbols of the values in mask separated by slashes
+func (lg *logic) valueNames(mask uint64) string {
+	var names []string
+	for m := mask; m != 0; m &= m - 1 {
+		names = append(names, string(lg.symbols[bits.TrailingZeros64(m)]))
+	}
+	return strings.Join(names, "/")
+}
+
+func (lg *logic) nakedSingle() *Step {
+	for cell, v := range lg.values {
+		if v != -1 || bits.OnesCount64(lg.candidates[cell]) != 1 {
+			continue
+		}
+		v = bits.TrailingZeros64(lg.candidates[cell])
+		return &Step{
+			Technique:   NakedSingle,
+			Cells:       []Cell{lg.cell(cell)},
+			Placements:  []Candidate{lg.place(cell, v)},
+			Description: fmt.Sprintf("%c is the only candidate left in %s", lg.symbols[v], lg.cellName(cell)),
+		}
+	}
+	return nil
+}
+
+func (lg *logic) hiddenSingle() *Step {
+	for u := range lg.units {
+		for v := 0; v < lg.size; v++ {
+			cells := lg.positions(u, v)
+			if len(cells) != 1 || lg.placed(u, v) {
+				continue
+			}
+			return &Step{
+				Technique:   HiddenSingle,
+				Cells:       []Cell{lg.cell(cells[0])},
+				Placements:  []Candidate{lg.place(cells[0], v)},
+				Description: fmt.Sprintf("%s is the only place left for %c in %s", lg.cellName(cells[0]), lg.symbols[v], lg.unitName(u)),
+			}
+		}
+	}
+	return nil
+}
+
+// nakedSubset looks for k cells of a unit sharing k candidates, these values are removed from the rest of the unit
+func (lg *logic) nakedSubset(k int, technique Technique) *Step {
+	for u, unit := range lg.units {
+		var cells []int
+		for _, cell := range unit {
+			count := bits.OnesCount64(lg.candidates[cell])
+			if lg.values[cell] == -1 && count >= 2 && count <= k {
+				cells = append(cells, cell)
+			}
+		}
+
+		var step *Step
+		combinations(cells, k, func(subset []int) bool {
+			var mask uint64
+			for _, cell := range subset {
+				mask |= lg.candidates[cell]
+			}
+			if bits.OnesCount64(mask) != k {
+				return false
+			}
+
+			var eliminations []Candidate
+			for _, cell := range unit {
+				if !containsCell(subset, cell) {
+					eliminations = append(eliminations, lg.eliminate(cell, mask)...)
+				}
+			}
+			if len(eliminations) == 0 {
+				return false
+			}
+			step = &Step{
+				Technique:    technique,
+				Cells:        lg.cellList(subset),
+				Eliminations: eliminations,
+				Description: fmt.Sprintf("%s can only hold %s, so these values are removed from the other cells of %s",
+					lg.cellNames(subset), lg.valueNames(mask), lg.unitName(u)),
+			}
+			return true
+		})
+		if step != nil {
+			return step
+		}
+	}
+	return nil
+}
+
+// hiddenSubset looks for k values confined to the same k cells of a unit, the other candidates of these cells are removed
+func (lg *logic) hiddenSubset(k int, technique Technique) *Step {
+	for u, unit := range lg.units {
+		if len(unit) != lg.size {
+			continue
+		}
+		var values []int
+		for v := 0; v < lg.size; v++ {
+			count := len(lg.positions(u, v))
+			if !lg.placed(u, v) && count >= 2 && count <= k {
+				values = append(values, v)
+			}
+		}
+
+		var step *Step
+		combinations(values, k, func(subset []int) bool {
+			var mask uint64
+			var cells []int
+			for _, v := range subset {
+				mask |= 1 << uint(v)
+				for _, cell := range lg.positions(u, v) {
+					if !containsCell(cells, cell) {
+						cells = append(cells, cell)
+					}
+				}
+			}
+			if len(cells) != k {
+				return false
+			}
+
+			var eliminations []Candidate
+			for _, cell := range cells {
+				eliminations = append(eliminations, lg.eliminate(cell, ^mask)...)
+			}
+			if len(eliminations) == 0 {
+				return false
+			}
+			step = &Step{
+				Technique:    technique,
+				Cells:        lg.cellList(cells),
+				Eliminations: eliminations,
+				Description: fmt.Sprintf("%s can only go in %s within %s, so the other candidates of these cells are removed",
+					lg.valueNames(mask), lg.cellNames(cells), lg.unitName(u)),
+			}
+			return true
+		})
+		if step != nil {
+			return step
+		}
+	}
+	return nil
+}
+
+func (lg *logic) pointingPair() *Step {
+	return lg.intersection(PointingPair, func(from, to unitKind) bool {
+		return from == boxUnit && to != boxUnit
+	})
+}
+
+func (lg *logic) boxLineReduction() *Step {
+	return lg.intersection(BoxLineReduction, func(from, to unitKind) bool {
+		return from != boxUnit && to == boxUnit
+	})
+}
+
+// intersection looks for a value whose places in a unit all belong to another unit,
+// the value is then removed from the cells of the other unit outside the first one
+func (lg *logic) intersection(technique Technique, allowed func(from, to unitKind) bool) *Step {
+	for u := range lg.units {
+		for v := 0; v < lg.size; v++ {
+			cells := lg.positions(u, v)
+			if len(cells) < 2 {
+				continue
+			}
+			for _, w := range lg.cellUnits[cells[0]] {
+				if w == u || !allowed(lg.kinds[u], lg.kinds[w]) || !allInUnit(lg.layout, cells, w) {
+					continue
+				}
+				var eliminations []Candidate
+				for _, cell := range lg.units[w] {
+					if !lg.inUnit(cell, u) {
+						eliminations = append(eliminations, lg.eliminate(cell, 1<<uint(v))...)
+					}
+				}
+				if len(eliminations) == 0 {
+					continue
+				}
+				return &Step{
+					Technique:    technique,
+					Cells:        lg.cellList(cells),
+					Eliminations: eliminations,
+					Description: fmt.Sprintf("%c can only go in %s within %s, so it is removed from the rest of %s",
+						lg.symbols[v], lg.cellNames(cells), lg.unitName(u), lg.unitName(w)),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fish looks for k rows (resp. columns) where a value can only go in the same k columns (resp. rows),
+// the value is then removed from the other cells of these columns (resp. rows)
+func (lg *logic) fish(k int, technique Technique) *Step {
+	for v := 0; v < lg.size; v++ {
+		for _, base := range []unitKind{rowUnit, columnUnit} {
+			// cover[i] is the set of the crossing lines where v can go in the i-th base line
+			cover := make(map[int]uint64)
+			var lines []int
+			for u := range lg.units {
+				if lg.kinds[u] != base || lg.placed(u, v) {
+					continue
+				}
+				var mask uint64
+				for _, cell := range lg.positions(u, v) {
+					mask |= 1 << uint(lg.crossIndex(base, cell))
+				}
+				if count := bits.OnesCount64(mask); count >= 2 && count <= k {
+					cover[u] = mask
+					lines = append(lines, u)
+				}
+			}
+
+			var step *Step
+			combinations(lines, k, func(subset []int) bool {
+				var mask uint64
+				var cells []int
+				for _, u := range subset {
+					mask |= cover[u]
+					cells = append(cells, lg.positions(u, v)...)
+				}
+				if bits.OnesCount64(mask) != k {
+					return false
+				}
+
+				var eliminations []Candidate
+				for cell := range lg.values {
+					if mask&(1<<uint(lg.crossIndex(base, cell))) == 0 || containsUnitOf(lg.layout, subset, cell) {
+						continue
+					}
+					eliminations = append(eliminations, lg.eliminate(cell, 1<<uint(v))...)
+				}
+				if len(eliminations) == 0 {
+					return false
+				}
+				names := make([]string, len(subset))
+				for i, u := range subset {
+					names[i] = lg.unitName(u)
+				}
+				step = &Step{
+					Technique:    technique,
+					Cells:        lg.cellList(cells),
+					Eliminations: eliminations,
+					Description: fmt.Sprintf("%c can only go in %s within %s, so it is removed from the rest of the crossing lines",
+						lg.symbols[v], lg.cellNames(cells), strings.Join(names, ", ")),
+				}
+				return true
+			})
+			if step != nil {
+				return step
+			}
+		}
+	}
+	return nil
+}
+
+// crossIndex returns the index of the line crossing the base lines the cell belongs to:
+// its column for row based fishes, its row for column based ones
+func (lg *logic) crossIndex(base unitKind, cell int) int {
+	if base == rowUnit {
+		return cell % lg.size
+	}
+	return cell / lg.size
+}
+
+// xyWing looks for a pivot cell with candidates x/y seeing two pincers with candidates x/z and y/z,
+// one of the pincers holds z so z is removed from the cells seeing both pincers
+func (lg *logic) xyWing() *Step {
+	for pivot, v := range lg.values {
+		if v != -1 || bits.OnesCount64(lg.candidates[pivot]) != 2 {
+			continue
+		}
+		for _, a := range lg.peers[pivot] {
+			if !lg.isPincer(pivot, a) {
+				continue
+			}
+			for _, b := range lg.peers[pivot] {
+				if b == a || !lg.isPincer(pivot, b) {
+					continue
+				}
+				z := lg.candidates[a] &^ lg.candidates[pivot]
+				if lg.candidates[b]&^lg.candidates[pivot] != z || lg.candidates[a]&lg.candidates[b] != z {
+					continue
+				}
+
+				var eliminations []Candidate
+				for _, cell := range lg.peers[a] {
+					if cell != b && cell != pivot && lg.sees(cell, b) {
+						eliminations = append(eliminations, lg.eliminate(cell, z)...)
+					}
+				}
+				if len(eliminations) == 0 {
+					continue
+				}
+				return &Step{
+					Technique:    XYWing,
+					Cells:        lg.cellList([]int{pivot, a, b}),
+					Eliminations: eliminations,
+					Description: fmt.Sprintf("whatever the value of %s, either %s or %s holds %s, so it is removed from the cells seeing both",
+						lg.cellName(pivot), lg.cellName(a), lg.cellName(b), lg.valueNames(z)),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isPincer returns true if the empty cell has two candidates, exactly one of them shared with the pivot
+func (lg *logic) isPincer(pivot, cell int) bool {
+	return lg.values[cell] == -1 && bits.OnesCount64(lg.candidates[cell]) == 2 &&
+		bits.OnesCount64(lg.candidates[cell]&lg.candidates[pivot]) == 1
+}
+
+// simpleColouring follows the chains of units where a value has exactly two places, alternating two colours.
+// One of the colours holds the value: if two cells of the same colour see each other, the value is removed from
+// all the cells of that colour, otherwise it is removed from the cells seeing both colours.
+func (lg *logic) simpleColouring() *Step {
+	for v := 0; v < lg.size; v++ {
+		bit := uint64(1) << uint(v)
+		links := make(map[int][]int)
+		for u := range lg.units {
+			if cells := lg.positions(u, v); len(cells) == 2 {
+				links[cells[0]] = append(links[cells[0]], cells[1])
+				links[cells[1]] = append(links[cells[1]], cells[0])
+			}
+		}
+
+		colour := make(map[int]int)
+		for cell := range lg.values {
+			if _, ok := links[cell]; !ok {
+				continue
+			}
+			if _, ok := colour[cell]; ok {
+				continue
+			}
+
+			// colour the chain starting from this cell
+			chain := []int{cell}
+			colour[cell] = 0
+			for i := 0; i < len(chain); i++ {
+				for _, next := range links[chain[i]] {
+					if _, ok := colour[next]; !ok {
+						colour[next] = 1 - colour[chain[i]]
+						chain = append(chain, next)
+					}
+				}
+			}
+			if len(chain) < 3 {
+				continue
+			}
+
+			if step := lg.colourWrap(v, chain, colour); step != nil {
+				return step
+			}
+
+			var eliminations []Candidate
+			for other, value := range lg.values {
+				if value != -1 || lg.candidates[other]&bit == 0 {
+					continue
+				}
+				if _, ok := colour[other]; ok && containsCell(chain, other) {
+					continue
+				}
+				var seen [2]bool
+				for _, c := range chain {
+					if lg.sees(other, c) {
+						seen[colour[c]] = true
+					}
+				}
+				if seen[0] && seen[1] {
+					eliminations = append(eliminations, lg.eliminate(other, bit)...)
+				}
+			}
+			if len(eliminations) > 0 {
+				return &Step{
+					Technique:    SimpleColouring,
+					Cells:        lg.cellList(chain),
+					Eliminations: eliminations,
+					Description: fmt.Sprintf("%c is either in all the cells of one colour of the chain %s or all the cells of the other, so it is removed from the cells seeing both colours",
+						lg.symbols[v], lg.cellNames(chain)),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// colourWrap removes v from the cells of a colour of the chain if two of them see each other
+func (lg *logic) colourWrap(v int, chain []int, colour map[int]int) *Step {
+	for i, a := range chain {
+		for _, b := range chain[i+1:] {
+			if colour[a] != colour[b] || !lg.sees(a, b) {
+				continue
+			}
+			var eliminations []Candidate
+			for _, cell := range chain {
+				if colour[cell] == colour[a] {
+					eliminations = append(eliminations, lg.eliminate(cell, 1<<uint(v))...)
+				}
+			}
+			return &Step{
+				Technique:    SimpleColouring,
+				Cells:        lg.cellList(chain),
+				Eliminations: eliminations,
+				Description: fmt.Sprintf("%s and %s have the same colour in the chain %s and see each other, so %c is removed from all the cells of that colour",
+					lg.cellName(a), lg.cellName(b), lg.cellNames(chain), lg.symbols[v]),
+			}
+		}
+	}
+	return nil
+}
+
+// combinations calls f with every k-sized subset of items, in order, until f returns true
+func combinations(items []int, k int, f func([]int) bool) bool {
+	subset := make([]int, 0, k)
+	var pick func(start int) bool
+	pick = func(start int) bool {
+		if len(subset) == k {
+			return f(subset)
+		}
+		for i := start; i <= len(items)-(k-len(subset)); i++ {
+			subset = append(subset, items[i])
+			if pick(i + 1) {
+				return true
+			}
+			subset = subset[:len(subset)-1]
+		}
+		return false
+	}
+	return pick(0)
+}
+
+func containsCell(cells []int, cell int) bool {
+	for _, c := range cells {
+		if c == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// allInUnit returns true if all the cells belong to the unit u
+func allInUnit(l *layout, cells []int, u int) bool {
+	for _, cell := range cells {
+		if !l.inUnit(cell, u) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsUnitOf returns true if the cell belongs to one of the units
+func containsUnitOf(l *layout, units []int, cell int) bool {
+	for _, u := range units {
+		if l.inUnit(cell, u) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package sudoku
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// emptyLogic returns the pencil marks of an empty 9x9 grid, where every cell can hold every value
+func emptyLogic() *logic {
+	sG, err := New(9, 3, 3)
+	Expect(err).To(BeNil())
+	lg, err := newLogic(sG)
+	Expect(err).To(BeNil())
+	return lg
+}
+
+// removeCandidate removes the value v from the cells (row, col) of the given rows and columns
+func removeCandidate(lg *logic, v int, rows, cols []int) {
+	for _, row := range rows {
+		for _, col := range cols {
+			lg.candidates[row*lg.size+col] &^= 1 << uint(v)
+		}
+	}
+}
+
+func without(all []int, excluded ...int) []int {
+	var res []int
+	for _, i := range all {
+		if !containsCell(excluded, i) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+var nine = []int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+
+const easyPuzzle = "..3.2.6..9..3.5..1..18.64....81.29..7.......8..67.82....26.95..8..2.3..9..5.1.3.."
+
+var _ = Describe("Logical solver", func() {
+	It("solves an easy puzzle with singles only", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		solution, err := sG.SolveLogically()
+		Expect(err).To(BeNil())
+		Expect(solution.Stuck).To(BeFalse())
+		Expect(isSolved(sG)).To(BeTrue())
+		Expect(solution.Steps).To(HaveLen(49))
+		for _, step := range solution.Steps {
+			Expect(step.Technique).To(BeElementOf(NakedSingle, HiddenSingle))
+			Expect(step.Placements).To(HaveLen(1))
+			Expect(step.Description).NotTo(BeEmpty())
+		}
+	})
+
+	It("reports being stuck instead of guessing", func() {
+		sG := parseGrid(9, 3, hardPuzzle)
+		solution, err := sG.SolveLogically()
+		Expect(err).To(BeNil())
+		Expect(solution.Stuck).To(BeTrue())
+		Expect(isSolved(sG)).To(BeFalse())
+	})
+
+	It("reports stuck on a puzzle with several solutions", func() {
+		sG, err := New(4, 2, 2)
+		Expect(err).To(BeNil())
+		solution, err := sG.SolveLogically()
+		Expect(err).To(BeNil())
+		Expect(solution.Stuck).To(BeTrue())
+		Expect(solution.Steps).To(BeEmpty())
+	})
+
+	It("returns an error when the clues contradict each other", func() {
+		sG, err := New(9, 3, 3)
+		Expect(err).To(BeNil())
+		sG.Set(0, 0, '5')
+		sG.Set(0, 8, '5')
+		_, err = sG.SolveLogically()
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("only makes deductions consistent with the solution", func() {
+		used := map[Technique]int{}
+		for i := 0; i < 30; i++ {
+			sG, err := GenerateSudokuGrid(9, 3, 3)
+			Expect(err).To(BeNil())
+			expected := sG.Clone()
+			Expect(sG.SetGridToLevel("robot", WithUniqueSolution())).To(Succeed())
+
+			solution, err := sG.SolveLogically()
+			Expect(err).To(BeNil())
+			for _, step := range solution.Steps {
+				used[step.Technique]++
+				for _, p := range step.Placements {
+					Expect(p.Value).To(Equal(expected.Grid[p.Row][p.Col]), "%s: %s", step.Technique, step.Description)
+				}
+				for _, e := range step.Eliminations {
+					Expect(e.Value).NotTo(Equal(expected.Grid[e.Row][e.Col]), "%s: %s", step.Technique, step.Description)
+				}
+			}
+			if !solution.Stuck {
+				Expect(sG.Grid).To(Equal(expected.Grid))
+			}
+		}
+		Expect(used[NakedSingle]).To(BeNumerically(">", 0))
+		Expect(used[HiddenSingle]).To(BeNumerically(">", 0))
+	})
+
+	Context("Techniques", func() {
+		It("finds naked pairs", func() {
+			lg := emptyLogic()
+			lg.candidates[0] = 0b11
+			lg.candidates[1] = 0b11
+
+			step := lg.nakedSubset(2, NakedPair)
+			Expect(step).NotTo(BeNil())
+			Expect(step.Cells).To(Equal([]Cell{{0, 0}, {0, 1}}))
+			Expect(step.Eliminations).To(HaveLen(14))
+			Expect(lg.candidates[2]).To(Equal(uint64(0b111111100)))
+		})
+
+		It("finds hidden pairs", func() {
+			lg := emptyLogic()
+			removeCandidate(lg, 0, []int{0}, without(nine, 0, 1))
+			removeCandidate(lg, 1, []int{0}, without(nine, 0, 1))
+
+			step := lg.hiddenSubset(2, HiddenPair)
+			Expect(step).NotTo(BeNil())
+			Expect(step.Cells).To(Equal([]Cell{{0, 0}, {0, 1}}))
+			Expect(step.Eliminations).To(HaveLen(14))
+			Expect(lg.candidates[0]).To(Equal(uint64(0b11)))
+			Expect(lg.candidates[1]).To(Equal(uint64(0b11)))
+		})
+
+		It("finds pointing pairs", func() {
+			lg := emptyLogic()
+			removeCandidate(lg, 4, []int{1, 2}, []int{0, 1, 2})
+
+			step := lg.pointingPair()
+			Expect(step).NotTo(BeNil())
+			Expect(step.Cells).To(HaveLen(3))
+			Expect(step.Eliminations).To(HaveLen(6))
+			for _, e := range step.Eliminations {
+				Expect(e.Row).To(Equal(0))
+				Expect(e.Col).To(BeNumerically(">=", 3))
+				Expect(e.Value).To(Equal('5'))
+			}
+		})
+
+		It("finds box-line reductions", func() {
+			lg := emptyLogic()
+			removeCandidate(lg, 4, []int{0}, without(nine, 0, 1))
+
+			step := lg.boxLineReduction()
+			Expect(step).NotTo(BeNil())
+			Expect(step.Cells).To(Equal([]Cell{{0, 0}, {0, 1}}))
+			Expect(step.Eliminations).To(HaveLen(6))
+			for _, e := range step.Eliminations {
+				Expect(e.Row).To(BeElementOf(1, 2))
+			}
+		})
+
+		It("finds x-wings", func() {
+			lg := emptyLogic()
+			removeCandidate(lg, 0, []int{0, 4}, without(nine, 1, 7))
+
+			step := lg.fish(2, XWing)
+			Expect(step).NotTo(BeNil())
+			Expect(step.Cells).To(Equal([]Cell{{0, 1}, {0, 7}, {4, 1}, {4, 7}}))
+			Expect(step.Eliminations).To(HaveLen(14))
+			for _, e := range step.Eliminations {
+				Expect(e.Col).To(BeElementOf(1, 7))
+			}
+		})
+
+		It("finds swordfishes", func() {
+			lg := emptyLogic()
+			removeCandidate(lg, 0, []int{0}, without(nine, 1, 4))
+			removeCandidate(lg, 0, []int{3}, without(nine, 4, 7))
+			removeCandidate(lg, 0, []int{6}, without(nine, 1, 7))
+			Expect(lg.fish(2, XWing)).To(BeNil())
+
+			step := lg.fish(3, Swordfish)
+			Expect(step).NotTo(BeNil())
+			Expect(step.Cells).To(HaveLen(6))
+			Expect(step.Eliminations).To(HaveLen(18))
+		})
+
+		It("finds xy-wings", func() {
+			lg := emptyLogic()
+			lg.candidates[0*9+0] = 0b011 // pivot 1/2
+			lg.candidates[0*9+5] = 0b101 // pincer 1/3
+			lg.candidates[5*9+0] = 0b110 // pincer 2/3
+
+			step := lg.xyWing()
+			Expect(step).NotTo(BeNil())
+			Expect(step.Cells).To(ConsistOf(Cell{0, 0}, Cell{0, 5}, Cell{5, 0}))
+			Expect(step.Eliminations).To(Equal([]Candidate{{Cell{5, 5}, '3'}}))
+		})
+
+		It("finds simple colouring traps", func() {
+			lg := emptyLogic()
+			// 1 has two places in row 0, column 4 and row 4: r1c1 - r1c5 - r5c5 - r5c2
+			removeCandidate(lg, 0, []int{0}, without(nine, 0, 4))
+			removeCandidate(lg, 0, nine, []int{4})
+			lg.candidates[0*9+4] |= 1
+			lg.candidates[4*9+4] |= 1
+			removeCandidate(lg, 0, []int{4}, without(nine, 1, 4))
+
+			step := lg.simpleColouring()
+			Expect(step).NotTo(BeNil())
+			Expect(step.Cells).To(ConsistOf(Cell{0, 0}, Cell{0, 4}, Cell{4, 4}, Cell{4, 1}))
+			// r1c1 and r5c2 have different colours, the cells seeing both of them lose 1
+			Expect(step.Eliminations).To(ConsistOf(
+				Candidate{Cell{1, 1}, '1'}, Candidate{Cell{2, 1}, '1'},
+				Candidate{Cell{3, 0}, '1'}, Candidate{Cell{5, 0}, '1'},
+			))
+		})
+	})
+})
@@ -0,0 +1,620 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Overlap declares that a rectangle of cells of a grid is the same as a rectangle of the same dimensions of
+// another grid, the shared cells hold the same value in both grids
+type Overlap struct {
+	Grids  [2]int  `json:"grids"`  // indexes of the two grids in MultiGrid.Grids
+	Cells  [2]Cell `json:"cells"`  // top-left cell of the rectangle in each grid
+	Height int     `json:"height"` // number of rows of the rectangle
+	Width  int     `json:"width"`  // number of columns of the rectangle
+}
+
+// MultiGrid is a puzzle made of several grids of the same size sharing some of their cells, such as a Samurai sudoku.
+// Each grid follows its own rules, and the grids are laid out on a canvas by their overlaps.
+type MultiGrid struct {
+	Grids    []*SudokuGrid `json:"grids"`
+	Overlaps []Overlap     `json:"overlaps"`
+	Seed     *int64        `json:"seed,omitempty"` // seed the grids were generated from, see WithSeed
+}
+
+// Samurai grids are given in this order by NewSamurai, the center grid shares a corner subgrid with each other one
+const (
+	SamuraiTopLeft = iota
+	SamuraiTopRight
+	SamuraiCenter
+	SamuraiBottomLeft
+	SamuraiBottomRight
+)
+
+// maxSamuraiSize is the size of the largest grids of the samurai sudokus made by GenerateSamurai, the solver seldom
+// completes five larger grids at once within the budget of a request
+const maxSamuraiSize = 9
+
+// NewMultiGrid returns the MultiGrid made of the given grids sharing the cells of the overlaps
+func NewMultiGrid(grids []*SudokuGrid, overlaps []Overlap) (*MultiGrid, error) {
+	m := &MultiGrid{Grids: grids, Overlaps: overlaps}
+	if err := m.Valid(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewSamurai returns an empty Samurai sudoku: five size x size grids, the center one sharing each of its corner
+// subgrids with the opposite corner subgrid of another grid. The values of the cells are the given symbols,
+// DefaultSymbols if empty.
+func NewSamurai(size, partitionWidth, partitionHeight int, symbols string) (*MultiGrid, error) {
+	grids := make([]*SudokuGrid, 5)
+	for i := range grids {
+		sG, err := NewWithSymbols(size, partitionWidth, partitionHeight, symbols)
+		if err != nil {
+			return nil, err
+		}
+		grids[i] = sG
+	}
+	// the corner grids would overlap one another with a single subgrid per row or column
+	if size < 2*partitionWidth || size < 2*partitionHeight {
+		return nil, fmt.Errorf("%w: a samurai sudoku needs at least 2 subgrids per row and per column", ErrInvalidDimensions)
+	}
+
+	top, left := 0, 0
+	bottom, right := size-partitionHeight, size-partitionWidth
+	corner := func(grid int, cell, center Cell) Overlap {
+		return Overlap{
+			Grids:  [2]int{grid, SamuraiCenter},
+			Cells:  [2]Cell{cell, center},
+			Height: partitionHeight,
+			Width:  partitionWidth,
+		}
+	}
+	return NewMultiGrid(grids, []Overlap{
+		corner(SamuraiTopLeft, Cell{Row: bottom, Col: right}, Cell{Row: top, Col: left}),
+		corner(SamuraiTopRight, Cell{Row: bottom, Col: left}, Cell{Row: top, Col: right}),
+		corner(SamuraiBottomLeft, Cell{Row: top, Col: right}, Cell{Row: bottom, Col: left}),
+		corner(SamuraiBottomRight, Cell{Row: top, Col: left}, Cell{Row: bottom, Col: right}),
+	})
+}
+
+// Valid returns an error if a grid isn't valid, the grids differ in size or symbols, or the overlaps do not lay the
+// grids out on a canvas without crossing. Cages and constraints are not supported in a MultiGrid.
+func (m *MultiGrid) Valid() error {
+	_, err := m.shape()
+	return err
+}
+
+// shape holds the layout of the grids of a MultiGrid on their canvas
+type shape struct {
+	rows, cols int
+	origins    []Cell  // position on the canvas of the top-left cell of each grid
+	ids        [][]int // index of each cell of each grid among the cells of the canvas, in row-major order
+	cells      []int   // position on the canvas of each cell, in row-major order
+}
+
+// shape validates the MultiGrid and returns its layout on the canvas
+func (m *MultiGrid) shape() (*shape, error) {
+	if len(m.Grids) == 0 {
+		return nil, fmt.Errorf("%w: a multi-grid needs at least one grid", ErrInvalidDimensions)
+	}
+	for i, sG := range m.Grids {
+		if sG == nil {
+			return nil, fmt.Errorf("%w: grid %d is missing", ErrInvalidDimensions, i)
+		}
+		if err := sG.Valid(); err != nil {
+			return nil, fmt.Errorf("grid %d: %w", i, err)
+		}
+		if len(sG.Cages) > 0 {
+			return nil, fmt.Errorf("%w: grid %d has cages, they are not supported in a multi-grid", ErrInvalidCage, i)
+		}
+		if len(sG.Constraints) > 0 || len(sG.Inequalities) > 0 || len(sG.Clues) > 0 {
+			return nil, fmt.Errorf("%w: grid %d has constraints, they are not supported in a multi-grid", ErrInvalidConstraint, i)
+		}
+		if sG.Size != m.Grids[0].Size || string(sG.symbols()) != string(m.Grids[0].symbols()) {
+			return nil, fmt.Errorf("%w: grid %d does not have the size and the symbols of grid 0", ErrInvalidDimensions, i)
+		}
+	}
+	n := m.Grids[0].Size
+
+	// the position of each grid is derived from the overlaps, starting from the first grid
+	placed := make([]bool, len(m.Grids))
+	origins := make([]Cell, len(m.Grids))
+	placed[0] = true
+	for changed := true; changed; {
+		changed = false
+		for i, o := range m.Overlaps {
+			if err := m.validOverlap(i, o); err != nil {
+				return nil, err
+			}
+			a, b := o.Grids[0], o.Grids[1]
+			// the top-left cell of the rectangle is at the same position on the canvas in both grids
+			offset := Cell{Row: o.Cells[0].Row - o.Cells[1].Row, Col: o.Cells[0].Col - o.Cells[1].Col}
+			switch {
+			case placed[a] && placed[b]:
+				if origins[b] != (Cell{Row: origins[a].Row + offset.Row, Col: origins[a].Col + offset.Col}) {
+					return nil, fmt.Errorf("%w: overlap %d does not agree with the others on the positions of grids %d and %d", ErrInvalidOverlap, i, a, b)
+				}
+			case placed[a]:
+				origins[b] = Cell{Row: origins[a].Row + offset.Row, Col: origins[a].Col + offset.Col}
+				placed[b], changed = true, true
+			case placed[b]:
+				origins[a] = Cell{Row: origins[b].Row - offset.Row, Col: origins[b].Col - offset.Col}
+				placed[a], changed = true, true
+			}
+		}
+	}
+
+	s := &shape{origins: origins, ids: make([][]int, len(m.Grids))}
+	top, left := 0, 0
+	for i, origin := range origins {
+		if !placed[i] {
+			return nil, fmt.Errorf("%w: grid %d does not overlap the other grids", ErrInvalidOverlap, i)
+		}
+		if origin.Row < top {
+			top = origin.Row
+		}
+		if origin.Col < left {
+			left = origin.Col
+		}
+	}
+	for i := range s.origins {
+		s.origins[i].Row -= top
+		s.origins[i].Col -= left
+		if s.origins[i].Row+n > s.rows {
+			s.rows = s.origins[i].Row + n
+		}
+		if s.origins[i].Col+n > s.cols {
+			s.cols = s.origins[i].Col + n
+		}
+	}
+
+	// root finds the grid cell standing for all the cells declared to be the same as the cell, which is the index
+	// of the cell among all the cells of the grids
+	parent := make([]int, len(m.Grids)*n*n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	for _, o := range m.Overlaps {
+		for x := 0; x < o.Height; x++ {
+			for y := 0; y < o.Width; y++ {
+				a := o.Grids[0]*n*n + (o.Cells[0].Row+x)*n + o.Cells[0].Col + y
+				b := o.Grids[1]*n*n + (o.Cells[1].Row+x)*n + o.Cells[1].Col + y
+				parent[root(a)] = root(b)
+			}
+		}
+	}
+
+	// covered holds the first grid cell found at each position of the canvas, -1 if there is none
+	covered := make([]int, s.rows*s.cols)
+	for i := range covered {
+		covered[i] = -1
+	}
+	id := make([]int, s.rows*s.cols)
+	for g, origin := range s.origins {
+		s.ids[g] = make([]int, n*n)
+		for cell := range s.ids[g] {
+			x, y := origin.Row+cell/n, origin.Col+cell%n
+			pos := x*s.cols + y
+			if covered[pos] == -1 {
+				covered[pos] = g*n*n + cell
+				continue
+			}
+			if other := covered[pos] / (n * n); root(covered[pos]) != root(g*n*n+cell) {
+				return nil, fmt.Errorf("%w: grids %d and %d cross at r%dc%d of grid %d outside of an overlap", ErrInvalidOverlap, other, g, cell/n+1, cell%n+1, g)
+			}
+		}
+	}
+	for pos, c := range covered {
+		if c != -1 {
+			id[pos] = len(s.cells)
+			s.cells = append(s.cells, pos)
+		}
+	}
+	for g, origin := range s.origins {
+		for cell := range s.ids[g] {
+			s.ids[g][cell] = id[(origin.Row+cell/n)*s.cols+origin.Col+cell%n]
+		}
+	}
+	return s, nil
+}
+
+// validOverlap returns an error if the overlap does not join two distinct grids of the MultiGrid with a rectangle
+// lying within both of them
+func (m *MultiGrid) validOverlap(i int, o Overlap) error {
+	for _, g := range o.Grids {
+		if g < 0 || g >= len(m.Grids) {
+			return fmt.Errorf("%w: overlap %d refers to grid %d, must be between 0 and %d", ErrInvalidOverlap, i, g, len(m.Grids)-1)
+		}
+	}
+	if o.Grids[0] == o.Grids[1] {
+		return fmt.Errorf("%w: overlap %d joins grid %d with itself", ErrInvalidOverlap, i, o.Grids[0])
+	}
+	if o.Height <= 0 || o.Width <= 0 {
+		return fmt.Errorf("%w: overlap %d must have positive dimensions", ErrInvalidOverlap, i)
+	}
+	for k, c := range o.Cells {
+		sG := m.Grids[o.Grids[k]]
+		if err := sG.isValidIndex(c.Row, c.Col); err != nil {
+			return err
+		}
+		if err := sG.isValidIndex(c.Row+o.Height-1, c.Col+o.Width-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate returns a ValidationError if a grid holds invalid symbols or conflicting values, or if a cell shared by
+// several grids does not hold the same value in all of them
+func (m *MultiGrid) Validate() error {
+	s, err := m.shape()
+	if err != nil {
+		return err
+	}
+	for i, sG := range m.Grids {
+		if err := sG.Validate(); err != nil {
+			return fmt.Errorf("grid %d: %w", i, err)
+		}
+	}
+	values := m.values(s)
+	for g, sG := range m.Grids {
+		for cell, id := range s.ids[g] {
+			v := sG.Grid[cell/sG.Size][cell%sG.Size]
+			if v != EMPTY_CELL && values[id] != v {
+				return fmt.Errorf("%w: r%dc%d of grid %d holds %c, it is %c in another grid", ErrConflict, cell/sG.Size+1, cell%sG.Size+1, g, v, values[id])
+			}
+		}
+	}
+	return nil
+}
+
+// values returns the value of each cell of the canvas, taken from the first grid where it is not empty
+func (m *MultiGrid) values(s *shape) []rune {
+	values := make([]rune, len(s.cells))
+	for i := range values {
+		values[i] = EMPTY_CELL
+	}
+	for g, sG := range m.Grids {
+		for cell, id := range s.ids[g] {
+			if values[id] == EMPTY_CELL {
+				values[id] = sG.Grid[cell/sG.Size][cell%sG.Size]
+			}
+		}
+	}
+	return values
+}
+
+// set sets the cell of the canvas to the value in every grid sharing it
+func (m *MultiGrid) set(s *shape, id int, v rune) {
+	for g, sG := range m.Grids {
+		for cell, other := range s.ids[g] {
+			if other == id {
+				sG.Set(cell/sG.Size, cell%sG.Size, v)
+			}
+		}
+	}
+}
+
+// layout returns the layout of the cells of the canvas, made of the units of all the grids, along with the value
+// of each cell, -1 for the empty ones. It returns the error of gridLayout if a grid cannot be laid out, and a nil
+// layout without error if a shared cell is given different values, leaving the canvas without solution.
+func (m *MultiGrid) layout(s *shape) (*layout, []int, error) {
+	n := m.Grids[0].Size
+	cells := len(s.cells)
+	l := &layout{
+		size:            n,
+		cells:           cells,
+		symbols:         m.Grids[0].symbols(),
+		cellUnits:       make([][]int, cells),
+		cellCage:        make([]int, cells),
+		peers:           make([][]int, cells),
+		cellConstraints: make([][]int, cells),
+	}
+	for cell := range l.cellCage {
+		l.cellCage[cell] = -1
+	}
+
+	values := make([]int, cells)
+	for cell := range values {
+		values[cell] = -1
+	}
+	// a subgrid shared by two grids is a single unit
+	seen := make(map[string]bool)
+	for g, sG := range m.Grids {
+		gl, gridValues, err := gridLayout(sG)
+		if err != nil {
+			return nil, nil, fmt.Errorf("grid %d: %w", g+1, err)
+		}
+		for u, unit := range gl.units {
+			ids := make([]int, len(unit))
+			for i, cell := range unit {
+				ids[i] = s.ids[g][cell]
+			}
+			key := fmt.Sprint(ids)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			l.units = append(l.units, ids)
+			l.kinds = append(l.kinds, gl.kinds[u])
+			l.indexes = append(l.indexes, gl.indexes[u])
+		}
+		for cell, v := range gridValues {
+			id := s.ids[g][cell]
+			if v == -1 {
+				continue
+			}
+			if values[id] != -1 && values[id] != v {
+				return nil, nil, nil
+			}
+			values[id] = v
+		}
+	}
+	l.link()
+	return l, values, nil
+}
+
+// search runs the solver on the cells of the canvas, found is given the value of each cell of every solution
+func (m *MultiGrid) search(ctx context.Context, s Solver, found func(*shape, []int) bool) (bool, Stats, error) {
+	ls, ok := s.(layoutSearcher)
+	if !ok {
+		return false, Stats{}, fmt.Errorf("the %T solver does not support multi-grid puzzles", s)
+	}
+	sh, err := m.shape()
+	if err != nil {
+		return false, Stats{}, err
+	}
+	l, values, err := m.layout(sh)
+	if l == nil {
+		return false, Stats{}, err
+	}
+	stopped, stats := ls.searchLayout(ctx, l, values, func(solution []int) bool {
+		return found(sh, solution)
+	})
+	return stopped, stats, nil
+}
+
+// Solve solves the MultiGrid in-place, returns an error if no solution exist
+func (m *MultiGrid) Solve() error {
+	return m.SolveContext(context.Background())
+}
+
+// SolveContext is like Solve but gives up with the context error once ctx is done
+func (m *MultiGrid) SolveContext(ctx context.Context) error {
+	_, err := m.SolveWith(ctx, BacktrackingSolver{})
+	return err
+}
+
+// SolveWith solves the MultiGrid in-place using the given Solver, the cells shared by several grids are set to the
+// same value in all of them. It returns the search statistics and an error if no solution exist, ctx is done before
+// a solution is found or the Solver cannot solve a MultiGrid.
+func (m *MultiGrid) SolveWith(ctx context.Context, s Solver) (Stats, error) {
+	symbols := m.symbols()
+	found, stats, err := m.search(ctx, s, func(sh *shape, solution []int) bool {
+		for g, sG := range m.Grids {
+			for cell, id := range sh.ids[g] {
+				sG.Set(cell/sG.Size, cell%sG.Size, symbols[solution[id]])
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return stats, err
+	}
+	if err := contextError(ctx); err != nil && !found {
+		return stats, err
+	}
+	if !found {
+		return stats, ErrNoSolution
+	}
+	return stats, nil
+}
+
+// CountSolutionsWith returns the number of solutions of the MultiGrid found with the given Solver, counting stops
+// once limit is reached. If limit is not positive, all the solutions are counted. If ctx is done before the search
+// is over, the count so far is returned along with the context error.
+func (m *MultiGrid) CountSolutionsWith(ctx context.Context, s Solver, limit int) (int, Stats, error) {
+	count := 0
+	stopped, stats, err := m.search(ctx, s, func(*shape, []int) bool {
+		count++
+		return limit > 0 && count >= limit
+	})
+	if err != nil {
+		return count, stats, err
+	}
+	if err := contextError(ctx); err != nil && !stopped {
+		return count, stats, err
+	}
+	return count, stats, nil
+}
+
+// IsUnique returns true if the MultiGrid has exactly one solution
+func (m *MultiGrid) IsUnique() bool {
+	count, _, err := m.CountSolutionsWith(context.Background(), BacktrackingSolver{}, 2)
+	return err == nil && count == 1
+}
+
+// symbols returns the values of the cells in order, the same in every grid
+func (m *MultiGrid) symbols() []rune {
+	return m.Grids[0].symbols()
+}
+
+// GenerateSamurai returns a solved Samurai sudoku made of size x size grids, see NewSamurai.
+// The symbols and the variant of the options apply to every grid, the other options are ignored.
+// Grids larger than maxSamuraiSize are rejected with ErrInvalidDimensions.
+func GenerateSamurai(size, partitionWidth, partitionHeight int, opts ...GeneratorOption) (*MultiGrid, error) {
+	return GenerateSamuraiContext(context.Background(), size, partitionWidth, partitionHeight, opts...)
+}
+
+// GenerateSamuraiContext is like GenerateSamurai but gives up with the context error once ctx is done
+func GenerateSamuraiContext(ctx context.Context, size, partitionWidth, partitionHeight int, opts ...GeneratorOption) (*MultiGrid, error) {
+	if size > maxSamuraiSize {
+		return nil, fmt.Errorf("%w: samurai sudokus are generated up to %dx%d", ErrInvalidDimensions, maxSamuraiSize, maxSamuraiSize)
+	}
+	o := newGeneratorOptions(opts)
+
+	m, err := NewSamurai(size, partitionWidth, partitionHeight, o.symbols)
+	if err != nil {
+		return nil, err
+	}
+	// the grids share the source of their random choices
+	m.Seed = o.seed
+	r := (&SudokuGrid{rng: o.rand}).random()
+	for _, sG := range m.Grids {
+		sG.rng = r
+		sG.Variant = o.variant
+		if err := sG.Valid(); err != nil {
+			return nil, err
+		}
+	}
+
+	// the subgrids on the diagonal of the corner grids share no unit, the center grid included, they are filled
+	// at random and the solver completes the rest. One subgrid less is filled at each attempt, down to the empty
+	// grids which always have a solution.
+	diagonal := size / partitionHeight
+	if stacks := size / partitionWidth; stacks < diagonal {
+		diagonal = stacks
+	}
+	for attempt := 0; attempt <= generationRestarts; attempt++ {
+		subgrids, maxNodes := diagonal-attempt, generationRestartNodes
+		if subgrids < 0 || attempt == generationRestarts {
+			subgrids = 0
+		}
+		if attempt == generationRestarts {
+			maxNodes = 0
+		}
+		filled := true
+		for _, g := range []int{SamuraiTopLeft, SamuraiTopRight, SamuraiBottomLeft, SamuraiBottomRight} {
+			sG := m.Grids[g]
+			sG.random().Shuffle(len(sG.allowedValues), func(i, j int) { sG.allowedValues[i], sG.allowedValues[j] = sG.allowedValues[j], sG.allowedValues[i] })
+			filled = filled && fillDiagonalSubgrids(sG, subgrids)
+		}
+		err = ErrNoSolution
+		if filled {
+			_, err = m.SolveWith(ctx, DLXSolver{maxNodes: maxNodes})
+		}
+		if err == nil {
+			return m, nil
+		}
+		if err := contextError(ctx); err != nil {
+			return nil, err
+		}
+		for _, sG := range m.Grids {
+			for _, c := range sG.filledCells() {
+				sG.Set(c.x, c.y, EMPTY_CELL)
+			}
+		}
+	}
+	return nil, errors.New("could not generate a valid samurai sudoku")
+}
+
+// SetToLevel empties cells of the MultiGrid to match the desired difficulty level, a cell shared by several grids
+// is emptied in all of them. Only WithUniqueSolution is supported among the options, WithGradedDifficulty is
+// treated like it.
+func (m *MultiGrid) SetToLevel(level string, opts ...GeneratorOption) error {
+	return m.SetToLevelContext(context.Background(), level, opts...)
+}
+
+// SetToLevelContext is like SetToLevel but gives up with the context error once ctx is done
+func (m *MultiGrid) SetToLevelContext(ctx context.Context, level string, opts ...GeneratorOption) error {
+	threshold, err := getLevelThreshold(level)
+	if err != nil {
+		return err
+	}
+	s, err := m.shape()
+	if err != nil {
+		return err
+	}
+	o := newGeneratorOptions(opts)
+	if o.rand != nil {
+		for _, sG := range m.Grids {
+			sG.rng = o.rand
+		}
+	}
+	r := m.Grids[0].random()
+	values := m.values(s)
+	if !o.unique && !o.graded {
+		for id := range values {
+			if r.Float64() < threshold {
+				m.set(s, id, EMPTY_CELL)
+			}
+		}
+		return nil
+	}
+
+	// like removeCluesUnique, a removal is only kept if the puzzle still has a unique solution
+	target := int(threshold * float64(len(values)))
+	cells := r.Perm(len(values))
+	removed := 0
+	for _, id := range cells {
+		if removed >= target {
+			break
+		}
+		if values[id] == EMPTY_CELL {
+			continue
+		}
+		m.set(s, id, EMPTY_CELL)
+		count, _, err := m.CountSolutionsWith(ctx, DLXSolver{}, 2)
+		if err != nil {
+			m.set(s, id, values[id])
+			return err
+		}
+		if count != 1 {
+			// the puzzle became ambiguous, put the clue back
+			m.set(s, id, values[id])
+			continue
+		}
+		removed++
+	}
+	return nil
+}
+
+// ToStringPrettify returns a formatted string representation of the MultiGrid, the grids drawn at their position
+// on the canvas with the outlines of their subgrids
+func (m *MultiGrid) ToStringPrettify() string {
+	s, err := m.shape()
+	if err != nil {
+		return err.Error()
+	}
+	n := m.Grids[0].Size
+	values := m.values(s)
+	cells := make([]rune, s.rows*s.cols)
+	group := make([]int, s.rows*s.cols)
+	for i := range group {
+		group[i] = -1
+	}
+	for id, pos := range s.cells {
+		cells[pos] = values[id]
+	}
+	// a cell shared by several grids is outlined with the subgrid of the first one
+	for g, sG := range m.Grids {
+		for cell, id := range s.ids[g] {
+			if pos := s.cells[id]; group[pos] == -1 {
+				group[pos] = g*n + sG.GetSubgridIndex(cell/n, cell%n)
+			}
+		}
+	}
+	var shaded []bool
+	for g, sG := range m.Grids {
+		for cell, on := range sG.shaded() {
+			if on {
+				if shaded == nil {
+					shaded = make([]bool, s.rows*s.cols)
+				}
+				shaded[s.cells[s.ids[g][cell]]] = true
+			}
+		}
+	}
+	o := &outline{rows: s.rows, cols: s.cols, cells: cells, group: group, shaded: shaded}
+	return o.String()
+}
@@ -0,0 +1,157 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// sharedCellsAgree returns true if the cells of each overlap of the MultiGrid hold the same value in both grids
+func sharedCellsAgree(m *MultiGrid) bool {
+	for _, o := range m.Overlaps {
+		a, b := m.Grids[o.Grids[0]], m.Grids[o.Grids[1]]
+		for x := 0; x < o.Height; x++ {
+			for y := 0; y < o.Width; y++ {
+				if a.Grid[o.Cells[0].Row+x][o.Cells[0].Col+y] != b.Grid[o.Cells[1].Row+x][o.Cells[1].Col+y] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// emptyGrids returns n empty 4x4 grids
+func emptyGrids(n int) []*SudokuGrid {
+	grids := make([]*SudokuGrid, n)
+	for i := range grids {
+		grids[i], _ = New(4, 2, 2)
+	}
+	return grids
+}
+
+var _ = Describe("MultiGrid", func() {
+	DescribeTable("generates samurai grids whose shared cells agree",
+		func(size, partitionWidth, partitionHeight int) {
+			m, err := GenerateSamurai(size, partitionWidth, partitionHeight)
+			Expect(err).To(BeNil())
+			Expect(m.Grids).To(HaveLen(5))
+			Expect(m.Overlaps).To(HaveLen(4))
+			for _, sG := range m.Grids {
+				Expect(isSolved(sG)).To(BeTrue())
+			}
+			Expect(sharedCellsAgree(m)).To(BeTrue())
+			Expect(m.Validate()).To(Succeed())
+		},
+		Entry("4x4", 4, 2, 2),
+		Entry("6x6", 6, 3, 2),
+		Entry("9x9", 9, 3, 3),
+	)
+
+	It("rejects samurai grids larger than 9x9", func() {
+		_, err := GenerateSamurai(12, 4, 3)
+		Expect(err).To(MatchError(ErrInvalidDimensions))
+		_, err = GenerateSamurai(16, 4, 4)
+		Expect(err).To(MatchError(ErrInvalidDimensions))
+	})
+
+	It("generates puzzles whose unique solution is found by every solver", func() {
+		m, err := GenerateSamurai(6, 3, 2)
+		Expect(err).To(BeNil())
+		solution := make([][][]rune, len(m.Grids))
+		for i, sG := range m.Grids {
+			solution[i] = sG.Clone().Grid
+		}
+		Expect(m.SetToLevel("medium", WithUniqueSolution())).To(Succeed())
+		Expect(m.IsUnique()).To(BeTrue())
+
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			b, err := json.Marshal(m)
+			Expect(err).To(BeNil())
+			puzzle := &MultiGrid{}
+			Expect(json.Unmarshal(b, puzzle)).To(Succeed())
+
+			_, err = puzzle.SolveWith(context.Background(), s)
+			Expect(err).To(BeNil())
+			for i, sG := range puzzle.Grids {
+				Expect(sG.Grid).To(Equal(solution[i]))
+			}
+		}
+	})
+
+	It("generates the same puzzle from the same seed", func() {
+		generate := func() string {
+			m, err := GenerateSamurai(4, 2, 2, WithSeed(42))
+			Expect(err).To(BeNil())
+			Expect(m.SetToLevel("medium")).To(Succeed())
+			b, err := json.Marshal(m)
+			Expect(err).To(BeNil())
+			return string(b)
+		}
+		first := generate()
+		Expect(first).To(ContainSubstring(`"seed":42`))
+		Expect(generate()).To(Equal(first))
+	})
+
+	It("keeps the values of the shared cells consistent", func() {
+		m, err := NewSamurai(4, 2, 2, "")
+		Expect(err).To(BeNil())
+		// r1c1 of the center grid is r3c3 of the top-left grid
+		m.Grids[SamuraiTopLeft].Set(2, 2, '1')
+		Expect(m.Solve()).To(Succeed())
+		Expect(m.Grids[SamuraiCenter].Grid[0][0]).To(Equal('1'))
+		Expect(sharedCellsAgree(m)).To(BeTrue())
+
+		m, err = NewSamurai(4, 2, 2, "")
+		Expect(err).To(BeNil())
+		m.Grids[SamuraiTopLeft].Set(2, 2, '1')
+		m.Grids[SamuraiCenter].Set(0, 0, '2')
+		Expect(m.Validate()).To(MatchError(ErrConflict))
+		Expect(m.Solve()).To(MatchError(ErrNoSolution))
+	})
+
+	DescribeTable("rejects invalid multi-grids",
+		func(grids []*SudokuGrid, overlaps []Overlap, expected error) {
+			_, err := NewMultiGrid(grids, overlaps)
+			Expect(err).To(MatchError(expected))
+		},
+		Entry("without grids", nil, nil, ErrInvalidDimensions),
+		Entry("with grids of different sizes",
+			append(emptyGrids(1), &SudokuGrid{Size: 1, PartitionWidth: 1, PartitionHeight: 1, Grid: [][]rune{{EMPTY_CELL}}}),
+			[]Overlap{{Grids: [2]int{0, 1}, Height: 1, Width: 1}}, ErrInvalidDimensions),
+		Entry("with a grid overlapping no other one", emptyGrids(2), nil, ErrInvalidOverlap),
+		Entry("with an overlap joining a grid with itself", emptyGrids(1),
+			[]Overlap{{Grids: [2]int{0, 0}, Height: 1, Width: 1}}, ErrInvalidOverlap),
+		Entry("with an overlap outside of a grid", emptyGrids(2),
+			[]Overlap{{Grids: [2]int{0, 1}, Cells: [2]Cell{{3, 3}, {0, 0}}, Height: 2, Width: 2}}, ErrOutOfBounds),
+		Entry("with grids crossing outside of the overlaps", emptyGrids(2),
+			[]Overlap{{Grids: [2]int{0, 1}, Cells: [2]Cell{{2, 2}, {0, 0}}, Height: 1, Width: 1}}, ErrInvalidOverlap),
+		Entry("with overlaps disagreeing on the positions", emptyGrids(2),
+			[]Overlap{
+				{Grids: [2]int{0, 1}, Cells: [2]Cell{{3, 3}, {0, 0}}, Height: 1, Width: 1},
+				{Grids: [2]int{1, 0}, Cells: [2]Cell{{0, 0}, {0, 3}}, Height: 1, Width: 1},
+			}, ErrInvalidOverlap),
+		Entry("with constraints", []*SudokuGrid{constrainedGrid()}, nil, ErrInvalidConstraint),
+	)
+
+	It("needs two subgrids per row and per column for a samurai", func() {
+		_, err := NewSamurai(4, 4, 1, "")
+		Expect(err).To(MatchError(ErrInvalidDimensions))
+	})
+
+	It("draws the grids at their position on the canvas", func() {
+		m, err := NewSamurai(9, 3, 3, "")
+		Expect(err).To(BeNil())
+		m.Grids[SamuraiCenter].Set(3, 0, '5')
+		lines := strings.Split(strings.TrimSuffix(m.ToStringPrettify(), "\n"), "\n")
+		Expect(lines).To(HaveLen(2*21 + 1))
+		Expect(lines[0]).To(Equal("+" + strings.Repeat("---+", 9) + strings.Repeat(" ", 11) + "+" + strings.Repeat("---+", 9)))
+		// the 10th row of the canvas only crosses the center grid
+		Expect(lines[2*9+1]).To(Equal(strings.Repeat(" ", 24) + "|  5   .   .|  .   .   .|  .   .   .|"))
+	})
+})
@@ -0,0 +1,582 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultSolver is the name of the Solver used when none is specified
+const DefaultSolver = "backtracking"
+
+// Stats describes the work done by a Solver during a search
+type Stats struct {
+	Nodes      int `json:"nodes"`      // number of search nodes visited
+	Backtracks int `json:"backtracks"` // number of branches abandoned, either dead ends or already reported solutions
+}
+
+// Solver is a sudoku solving engine
+type Solver interface {
+	// Search explores the solutions of the SudokuGrid, filling it in and calling found for each solution.
+	// The search stops as soon as found returns true, in which case Search returns true.
+	// It also gives up, returning false, once ctx is done.
+	Search(ctx context.Context, sG *SudokuGrid, found func() bool) (bool, Stats)
+}
+
+var (
+	solversMu sync.RWMutex
+	solvers   = map[string]Solver{}
+)
+
+func init() {
+	RegisterSolver("backtracking", BacktrackingSolver{})
+	RegisterSolver("dlx", DLXSolver{})
+}
+
+// RegisterSolver makes a Solver available under the given name, it panics if the name is already taken
+func RegisterSolver(name string, s Solver) {
+	solversMu.Lock()
+	defer solversMu.Unlock()
+	if s == nil {
+		panic("sudoku: RegisterSolver solver is nil")
+	}
+	if _, dup := solvers[name]; dup {
+		panic("sudoku: RegisterSolver called twice for solver " + name)
+	}
+	solvers[name] = s
+}
+
+// GetSolver returns the Solver registered under the given name
+func GetSolver(name string) (Solver, error) {
+	solversMu.RLock()
+	s, ok := solvers[name]
+	solversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: must be one of the supported solvers (%s)", ErrUnknownSolver, strings.Join(Solvers(), ", "))
+	}
+	return s, nil
+}
+
+// Solvers returns the sorted names of the registered solvers
+func Solvers() []string {
+	solversMu.RLock()
+	defer solversMu.RUnlock()
+	names := make([]string, 0, len(solvers))
+	for name := range solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BacktrackingSolver solves puzzles by depth-first search over per-cell candidate sets,
+// propagating naked and hidden singles and branching on the cell with the fewest candidates,
+// or on the cells left to a value of a unit when there are fewer of them.
+type BacktrackingSolver struct {
+	maxNodes int // number of search nodes after which the search gives up, unlimited if 0
+}
+
+// Search implements Solver
+func (s BacktrackingSolver) Search(ctx context.Context, sG *SudokuGrid, found func() bool) (bool, Stats) {
+	stopped, stats, _ := sG.search(ctx, s, found)
+	return stopped, stats
+}
+
+// searchLayout implements layoutSearcher
+func (s BacktrackingSolver) searchLayout(ctx context.Context, l *layout, values []int, found func([]int) bool) (bool, Stats) {
+	var stats Stats
+	b, ok := newBoard(l, values)
+	if !ok {
+		return false, stats
+	}
+	stopped := b.search(ctx, &stats, s.maxNodes, func(solution *board) bool {
+		return found(solution.values)
+	})
+	return stopped, stats
+}
+
+// layoutSearcher is implemented by the solvers able to search any layout, such as the combined one of a
+// MultiGrid. The search starts from the given value of each cell, -1 for the empty ones, and found is given the
+// values of each solution, which are only valid during the call.
+type layoutSearcher interface {
+	searchLayout(ctx context.Context, l *layout, values []int, found func([]int) bool) (bool, Stats)
+}
+
+// maxSymbols is the largest number of distinct values a board can hold, one bit per value in a candidate set
+const maxSymbols = 64
+
+// unitKind tells what a unit of a layout is made of
+type unitKind int
+
+const (
+	rowUnit unitKind = iota
+	columnUnit
+	boxUnit
+	diagonalUnit
+	extraUnit
+)
+
+func (k unitKind) String() string {
+	switch k {
+	case rowUnit:
+		return "row"
+	case columnUnit:
+		return "column"
+	case diagonalUnit:
+		return "diagonal"
+	case extraUnit:
+		return "extra region"
+	}
+	return "box"
+}
+
+// layout holds the static structure of a board: which cells must hold distinct values
+type layout struct {
+	size      int // number of values
+	cells     int // number of cells, size * size for a single grid
+	symbols   []rune
+	units     [][]int    // groups of cells that must hold distinct values
+	kinds     []unitKind // kind of each unit
+	indexes   []int      // index of each unit among the units of the same kind
+	cellUnits [][]int    // indexes of the units each cell belongs to
+	cages     [][]int    // cells of the cages of a Killer Sudoku, they hold distinct values adding up to cageSums
+	cageSums  []int      // sum of each cage, the value v counting as v+1
+	cellCage  []int      // index of the cage of each cell, -1 if the cell is in no cage
+	peers     [][]int    // cells sharing at least one unit or a cage with each cell
+
+	constraints     []Constraint
+	related         [][][]int // cells related to each cell by each constraint, see Constraint.Related
+	cellConstraints [][]int   // indexes of the constraints relating each cell to other cells
+}
+
+// board is the compact representation of a SudokuGrid used by the solving engine.
+// Cells are indexed in row-major order and values are indexes into layout.symbols.
+type board struct {
+	*layout
+	values     []int    // value of each cell, -1 if the cell is empty
+	candidates []uint64 // bitset of the values each cell can still take
+}
+
+func newLayout(sG *SudokuGrid) *layout {
+	n := sG.Size
+	l := &layout{
+		size:      n,
+		cells:     n * n,
+		symbols:   sG.symbols(),
+		cellUnits: make([][]int, n*n),
+		cellCage:  make([]int, n*n),
+		peers:     make([][]int, n*n),
+
+		constraints:     sG.rules(),
+		related:         make([][][]int, len(sG.rules())),
+		cellConstraints: make([][]int, n*n),
+	}
+
+	rows := make([][]int, n)
+	cols := make([][]int, n)
+	boxes := make([][]int, n)
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			cell := x*n + y
+			rows[x] = append(rows[x], cell)
+			cols[y] = append(cols[y], cell)
+			box := sG.GetSubgridIndex(x, y)
+			boxes[box] = append(boxes[box], cell)
+		}
+	}
+	var diagonals [][]int
+	if sG.Variant == DiagonalVariant {
+		diagonals = make([][]int, 2)
+		for x := 0; x < n; x++ {
+			diagonals[0] = append(diagonals[0], x*n+x)
+			diagonals[1] = append(diagonals[1], x*n+n-1-x)
+		}
+	}
+	extras := make([][]int, len(sG.ExtraRegions))
+	for i, region := range sG.ExtraRegions {
+		for _, c := range region {
+			extras[i] = append(extras[i], c.Row*n+c.Col)
+		}
+	}
+	for kind, units := range [][][]int{rowUnit: rows, columnUnit: cols, boxUnit: boxes, diagonalUnit: diagonals, extraUnit: extras} {
+		for i, unit := range units {
+			l.units = append(l.units, unit)
+			l.kinds = append(l.kinds, unitKind(kind))
+			l.indexes = append(l.indexes, i)
+		}
+	}
+
+	for cell := range l.cellCage {
+		l.cellCage[cell] = -1
+	}
+	for c, cage := range sG.Cages {
+		cells := make([]int, len(cage.Cells))
+		for i, cell := range cage.Cells {
+			cells[i] = cell.Row*n + cell.Col
+			l.cellCage[cells[i]] = c
+		}
+		l.cages = append(l.cages, cells)
+		l.cageSums = append(l.cageSums, cage.Sum)
+	}
+
+	for k, c := range l.constraints {
+		l.related[k] = make([][]int, n*n)
+		for cell := range l.related[k] {
+			for _, other := range c.Related(n, Cell{Row: cell / n, Col: cell % n}) {
+				l.related[k][cell] = append(l.related[k][cell], other.Row*n+other.Col)
+			}
+			if len(l.related[k][cell]) > 0 {
+				l.cellConstraints[cell] = append(l.cellConstraints[cell], k)
+			}
+		}
+	}
+
+	l.link()
+	return l
+}
+
+// link fills cellUnits and peers from the units and the cages of the layout
+func (l *layout) link() {
+	for u, unit := range l.units {
+		for _, cell := range unit {
+			l.cellUnits[cell] = append(l.cellUnits[cell], u)
+		}
+	}
+
+	// seen[peer] == cell+1 marks the peers already collected for the current cell
+	seen := make([]int, l.cells)
+	for cell := range l.peers {
+		seen[cell] = cell + 1
+		groups := make([][]int, 0, len(l.cellUnits[cell])+1)
+		for _, u := range l.cellUnits[cell] {
+			groups = append(groups, l.units[u])
+		}
+		if c := l.cellCage[cell]; c != -1 {
+			groups = append(groups, l.cages[c])
+		}
+		for _, group := range groups {
+			for _, peer := range group {
+				if seen[peer] != cell+1 {
+					seen[peer] = cell + 1
+					l.peers[cell] = append(l.peers[cell], peer)
+				}
+			}
+		}
+	}
+}
+
+// gridLayout returns the layout of the SudokuGrid along with the value of each of its cells, -1 for the empty ones.
+// It returns an error matching ErrInvalidDimensions if the grid is too large for a board, or ErrInvalidSymbol if a
+// cell holds no symbol of the grid.
+func gridLayout(sG *SudokuGrid) (*layout, []int, error) {
+	if sG.Size > maxSymbols {
+		return nil, nil, fmt.Errorf("%w: grids larger than %dx%d are not supported", ErrInvalidDimensions, maxSymbols, maxSymbols)
+	}
+	l := newLayout(sG)
+	index := make(map[rune]int, len(l.symbols))
+	for v, symbol := range l.symbols {
+		index[symbol] = v
+	}
+	values := make([]int, l.cells)
+	for cell := range values {
+		symbol := sG.Grid[cell/l.size][cell%l.size]
+		if symbol == EMPTY_CELL {
+			values[cell] = -1
+			continue
+		}
+		v, ok := index[symbol]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %q in r%dc%d", ErrInvalidSymbol, symbol, cell/l.size+1, cell%l.size+1)
+		}
+		values[cell] = v
+	}
+	return l, values, nil
+}
+
+// apply writes the values of a single grid layout into the SudokuGrid, the empty cells are left unchanged
+func (l *layout) apply(sG *SudokuGrid, values []int) {
+	for cell, v := range values {
+		if v != -1 {
+			sG.Set(cell/l.size, cell%l.size, l.symbols[v])
+		}
+	}
+}
+
+// unitName returns a human readable name of the unit such as "row 3", indexes start from 1
+func (l *layout) unitName(u int) string {
+	return fmt.Sprintf("%s %d", l.kinds[u], l.indexes[u]+1)
+}
+
+// inUnit returns true if the cell belongs to the unit u
+func (l *layout) inUnit(cell, u int) bool {
+	for _, cu := range l.cellUnits[cell] {
+		if cu == u {
+			return true
+		}
+	}
+	return false
+}
+
+// sees returns true if the two distinct cells share a unit
+func (l *layout) sees(a, b int) bool {
+	for _, u := range l.cellUnits[a] {
+		if l.inUnit(b, u) {
+			return true
+		}
+	}
+	return false
+}
+
+// newBoard builds the board of the layout and propagates the given values, -1 for the empty cells,
+// returns false if they already contradict each other.
+func newBoard(l *layout, values []int) (*board, bool) {
+	b := &board{
+		layout:     l,
+		values:     make([]int, l.cells),
+		candidates: make([]uint64, l.cells),
+	}
+
+	all := uint64(1)<<uint(l.size) - 1
+	for cell := range b.values {
+		b.values[cell] = -1
+		b.candidates[cell] = all
+	}
+	for cell, v := range values {
+		if v != -1 && !b.assign(cell, v) {
+			return nil, false
+		}
+	}
+	for c := range b.cages {
+		if !b.restrictCage(c) {
+			return nil, false
+		}
+	}
+	if !b.restrictAll(b.values, b.candidates, b.eliminate) {
+		return nil, false
+	}
+	return b, true
+}
+
+func (b *board) clone() *board {
+	c := &board{
+		layout:     b.layout,
+		values:     make([]int, len(b.values)),
+		candidates: make([]uint64, len(b.candidates)),
+	}
+	copy(c.values, b.values)
+	copy(c.candidates, b.candidates)
+	return c
+}
+
+// assign places the value v in the cell and propagates the consequences to its peers,
+// returns false if this leads to a contradiction.
+func (b *board) assign(cell, v int) bool {
+	if b.values[cell] == v {
+		return true
+	}
+	if b.values[cell] != -1 || b.candidates[cell]&(1<<uint(v)) == 0 {
+		return false
+	}
+	b.values[cell] = v
+	b.candidates[cell] = 1 << uint(v)
+	for _, peer := range b.peers[cell] {
+		if !b.eliminate(peer, v) {
+			return false
+		}
+	}
+	if c := b.cellCage[cell]; c != -1 && !b.restrictCage(c) {
+		return false
+	}
+	return b.constrain(b.values, b.candidates, cell, b.eliminate)
+}
+
+// restrictCage removes from the empty cells of the cage c the values belonging to no combination of distinct
+// candidates adding up to the rest of its sum, returns false if there is no such combination
+func (b *board) restrictCage(c int) bool {
+	sum := b.cageSums[c]
+	var used uint64
+	var empty []int
+	for _, cell := range b.cages[c] {
+		if v := b.values[cell]; v != -1 {
+			sum -= v + 1
+			used |= 1 << uint(v)
+		} else {
+			empty = append(empty, cell)
+		}
+	}
+
+	possible := make([]uint64, len(empty))
+	if !b.cageCombinations(empty, possible, sum, used) {
+		return false
+	}
+	for i, cell := range empty {
+		for removed := b.candidates[cell] &^ possible[i]; removed != 0; removed &= removed - 1 {
+			if !b.eliminate(cell, bits.TrailingZeros64(removed)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cageCombinations looks for the distinct candidates of the cells, none of them in used, adding up to sum.
+// The values of every combination found are added to possible, it returns false if there is none.
+func (b *board) cageCombinations(cells []int, possible []uint64, sum int, used uint64) bool {
+	if len(cells) == 0 {
+		return sum == 0
+	}
+	// the other cells need at least 1+2+...+k and at most n+(n-1)+...+(n-k+1)
+	k := len(cells) - 1
+	low, high := k*(k+1)/2, k*(2*b.size-k+1)/2
+	found := false
+	for candidates := b.candidates[cells[0]] &^ used; candidates != 0; candidates &= candidates - 1 {
+		v := bits.TrailingZeros64(candidates)
+		rest := sum - v - 1
+		if rest < low {
+			break
+		}
+		if rest > high {
+			continue
+		}
+		if b.cageCombinations(cells[1:], possible[1:], rest, used|1<<uint(v)) {
+			possible[0] |= 1 << uint(v)
+			found = true
+		}
+	}
+	return found
+}
+
+// eliminate removes v from the candidates of the cell, then applies the naked single
+// and hidden single rules to the cell and its units, returns false on contradiction.
+func (b *board) eliminate(cell, v int) bool {
+	bit := uint64(1) << uint(v)
+	if b.candidates[cell]&bit == 0 {
+		return true
+	}
+	if b.values[cell] == v {
+		return false
+	}
+	b.candidates[cell] &^= bit
+
+	switch bits.OnesCount64(b.candidates[cell]) {
+	case 0:
+		return false
+	case 1:
+		// naked single: only one value is left for this cell
+		if b.values[cell] == -1 && !b.assign(cell, bits.TrailingZeros64(b.candidates[cell])) {
+			return false
+		}
+	}
+
+	// hidden single: v may have only one place left in the units of the cell
+	for _, u := range b.cellUnits[cell] {
+		place, count := -1, 0
+		for _, other := range b.units[u] {
+			if b.candidates[other]&bit != 0 {
+				place = other
+				count++
+			}
+		}
+		switch {
+		case count == 0:
+			return false
+		case count == 1 && b.values[place] == -1:
+			if !b.assign(place, v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// nextCell returns the empty cell with the fewest candidates (minimum remaining values), -1 if the board is full
+func (b *board) nextCell() int {
+	best, bestCount := -1, maxSymbols+1
+	for cell, v := range b.values {
+		if v != -1 {
+			continue
+		}
+		count := bits.OnesCount64(b.candidates[cell])
+		if count < bestCount {
+			best, bestCount = cell, count
+			if count <= 2 {
+				break
+			}
+		}
+	}
+	return best
+}
+
+// nextPlace returns the unit and the value not placed in it yet with the fewest empty cells left to hold it, along
+// with that number of cells, count is maxSymbols+1 if every value of every unit is placed
+func (b *board) nextPlace() (unit, v, count int) {
+	unit, v, count = -1, -1, maxSymbols+1
+	places := make([]int, b.size)
+	for u, cells := range b.units {
+		for i := range places {
+			places[i] = 0
+		}
+		for _, cell := range cells {
+			if b.values[cell] != -1 {
+				continue
+			}
+			for candidates := b.candidates[cell]; candidates != 0; candidates &= candidates - 1 {
+				places[bits.TrailingZeros64(candidates)]++
+			}
+		}
+		// the values placed in the unit have no empty cell left, the propagation fails before any other value does
+		for value, n := range places {
+			if n > 0 && n < count {
+				unit, v, count = u, value, n
+			}
+		}
+	}
+	return unit, v, count
+}
+
+// search explores the solutions of the board depth-first, calling found every time the board is complete.
+// The search stops as soon as found returns true, in which case search returns true. It gives up once ctx is done
+// or maxNodes nodes are visited, if maxNodes is positive.
+// It branches on the candidates of the cell with the fewest of them, unless a value of a unit has fewer cells left
+// to go to, in which case it branches on these cells: either way every solution is explored exactly once, and the
+// search does not get lost in the large grids with few givens.
+func (b *board) search(ctx context.Context, stats *Stats, maxNodes int, found func(*board) bool) bool {
+	if done(ctx) || (maxNodes > 0 && stats.Nodes >= maxNodes) {
+		return false
+	}
+	stats.Nodes++
+	cell := b.nextCell()
+	if cell == -1 {
+		return found(b)
+	}
+
+	var cells, values []int
+	for candidates := b.candidates[cell]; candidates != 0; candidates &= candidates - 1 {
+		cells, values = append(cells, cell), append(values, bits.TrailingZeros64(candidates))
+	}
+	if len(values) > 1 {
+		if u, v, count := b.nextPlace(); count < len(values) {
+			cells, values = cells[:0], values[:0]
+			for _, other := range b.units[u] {
+				if b.values[other] == -1 && b.candidates[other]&(1<<uint(v)) != 0 {
+					cells, values = append(cells, other), append(values, v)
+				}
+			}
+		}
+	}
+
+	for i, cell := range cells {
+		next := b.clone()
+		if next.assign(cell, values[i]) && next.search(ctx, stats, maxNodes, found) {
+			return true
+		}
+		if done(ctx) || (maxNodes > 0 && stats.Nodes >= maxNodes) {
+			return false
+		}
+		stats.Backtracks++
+	}
+	return false
+}
@@ -0,0 +1,385 @@
+package sudoku
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// patternGrid returns a solved SudokuGrid built from the classic shifted-rows pattern
+func patternGrid(size, partitionWidth, partitionHeight int) *SudokuGrid {
+	sG, err := New(size, partitionWidth, partitionHeight)
+	if err != nil {
+		panic(err)
+	}
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			v := (partitionWidth*(x%partitionHeight) + x/partitionHeight + y) % size
+			sG.Set(x, y, sG.allowedValues[v])
+		}
+	}
+	return sG
+}
+
+// blankCells empties the given fraction of the cells of the SudokuGrid, using a fixed seed
+func blankCells(sG *SudokuGrid, fraction float64, seed int64) *SudokuGrid {
+	r := rand.New(rand.NewSource(seed))
+	for x := 0; x < sG.Size; x++ {
+		for y := 0; y < sG.Size; y++ {
+			if r.Float64() < fraction {
+				sG.Set(x, y, EMPTY_CELL)
+			}
+		}
+	}
+	return sG
+}
+
+// parseGrid builds a SudokuGrid with square subgrids from a string listing its cells in row-major order
+func parseGrid(size, partition int, cells string) *SudokuGrid {
+	sG, err := New(size, partition, partition)
+	if err != nil {
+		panic(err)
+	}
+	for i, c := range []rune(cells) {
+		sG.Set(i/size, i%size, c)
+	}
+	return sG
+}
+
+// isSolved returns true if the SudokuGrid is full and no row, column or subgrid holds a value twice
+func isSolved(sG *SudokuGrid) bool {
+	rows := make([]map[rune]bool, sG.Size)
+	cols := make([]map[rune]bool, sG.Size)
+	boxes := make([]map[rune]bool, sG.Size)
+	for i := 0; i < sG.Size; i++ {
+		rows[i], cols[i], boxes[i] = map[rune]bool{}, map[rune]bool{}, map[rune]bool{}
+	}
+	for x := 0; x < sG.Size; x++ {
+		for y := 0; y < sG.Size; y++ {
+			v := sG.Grid[x][y]
+			box := sG.GetSubgridIndex(x, y)
+			if v == EMPTY_CELL || rows[x][v] || cols[y][v] || boxes[box][v] {
+				return false
+			}
+			rows[x][v], cols[y][v], boxes[box][v] = true, true, true
+		}
+	}
+	return true
+}
+
+const hardPuzzle = "8..........36......7..9.2...5...7.......457.....1...3...1....68..85...1..9....4.."
+
+const puzzle16x16 = "" +
+	"B.E41.C...2.85.." +
+	"..A........E..C." +
+	"G8.1A9E.B.7.6.F." +
+	".6CDB8.4A1G.2.9E" +
+	"9.G.6.4..B.2C..5" +
+	"...F.G.B..169E42" +
+	".5...18.G3EAB6.." +
+	".2...D.E5..9...." +
+	".4.3.F7A..5.1.28" +
+	".C158..G.2.F..69" +
+	"...2..6.8...G.A7" +
+	"..FG42...6C.53BD" +
+	"FG...E.D..9C.2.3" +
+	"D.5...2.F.3.4..C" +
+	"312......564.GEB" +
+	".E98..1.2...D..."
+
+const puzzle25x25 = "" +
+	"BFH4..19C.D.8NG5...AJ6K.L" +
+	".A9..8B.DEHLFOI.K.P..52.1" +
+	"P.I6.AG.H.5.3.9DJL.8F.NOB" +
+	"D.5G3.LF...A6..C...NE.789" +
+	"..LO..6.N...M.C19FG4HAD.I" +
+	"1..LPB.N...HJ63475F9IOED." +
+	"..C9..56E7N.O..ADP8K43.2." +
+	"N.OE..DGKP.C4.7M.J.I6B9HA" +
+	"...DF9...MK5PAENH.O21L.J." +
+	"I3AK71O..JM.2DF6G.BL8.C5." +
+	"3.8HO..J.16D.25..9..7...." +
+	".LD.JK7P96IMBGO2..4..H.1N" +
+	"C4N2B.8L..AJK1..6D.79..G5" +
+	".7.PMCE.BHF4.9...I..DK362" +
+	"6.FAID.5G4.37EH..KN1L8OM." +
+	".5.N.GPB1A87EK.942D.3.J.C" +
+	".....H.O.....3.8NACBKP5EG" +
+	".DK347..5FP...2L.6I.A1B.H" +
+	".PGCL69EID.1..AK3.J...4.8" +
+	"....A4.C32J...M..H1..7.LD" +
+	"5NJF8.C379OK1L4I..2M.DH.." +
+	"..3I1EHM..285.J..N9...L.O" +
+	"AH27E.FDL.9..MBPO465..1K3" +
+	"L.PMD241A...IHNJB..C.9..." +
+	".C4B65J.O.3P.FD78.L.2G.I."
+
+var _ = Describe("Solver", func() {
+	It("solves a hard 9x9 puzzle", func() {
+		sG := parseGrid(9, 3, hardPuzzle)
+		Expect(sG.Solve()).To(Succeed())
+		Expect(isSolved(sG)).To(BeTrue())
+		Expect(string(sG.Grid[0])).To(Equal("812753649"))
+		Expect(parseGrid(9, 3, hardPuzzle).IsUnique()).To(BeTrue())
+	})
+
+	It("keeps the clues of the puzzle", func() {
+		sG := parseGrid(16, 4, puzzle16x16)
+		clues := sG.Clone()
+
+		Expect(sG.Solve()).To(Succeed())
+		Expect(isSolved(sG)).To(BeTrue())
+		for x := range clues.Grid {
+			for y, v := range clues.Grid[x] {
+				if v != EMPTY_CELL {
+					Expect(sG.Grid[x][y]).To(Equal(v))
+				}
+			}
+		}
+	})
+
+	It("solves large grids", func() {
+		sG := parseGrid(25, 5, puzzle25x25)
+		Expect(sG.Solve()).To(Succeed())
+		Expect(isSolved(sG)).To(BeTrue())
+		Expect(parseGrid(25, 5, puzzle25x25).IsUnique()).To(BeTrue())
+	})
+
+	It("solves empty and sparse large grids with every solver", func() {
+		empty20, err := New(20, 5, 4)
+		Expect(err).To(BeNil())
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+			for _, input := range benchmarkInputs {
+				sG := parseGrid(input.size, input.partition, input.cells)
+				clues := sG.Clone()
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				_, err := sG.SolveWith(ctx, s)
+				cancel()
+				Expect(err).To(BeNil(), "%s %s", name, input.name)
+				Expect(isSolved(sG)).To(BeTrue(), "%s %s", name, input.name)
+				for x := range clues.Grid {
+					for y, v := range clues.Grid[x] {
+						if v != EMPTY_CELL {
+							Expect(sG.Grid[x][y]).To(Equal(v))
+						}
+					}
+				}
+			}
+
+			sG := empty20.Clone()
+			Expect(sG.SolveWith(context.Background(), s)).Error().To(BeNil())
+			Expect(isSolved(sG)).To(BeTrue())
+		}
+	})
+
+	It("agrees with the reference backtracking solver", func() {
+		for seed := int64(0); seed < 10; seed++ {
+			sG := blankCells(patternGrid(9, 3, 3), 0.55, seed)
+
+			reference := sG.Clone()
+			referenceCount := 0
+			reference.solve(reference.missingCells(), func() bool {
+				referenceCount++
+				return false
+			})
+
+			Expect(sG.CountSolutions(0)).To(Equal(referenceCount))
+		}
+	})
+
+	It("detects contradicting clues", func() {
+		sG, err := New(9, 3, 3)
+		Expect(err).To(BeNil())
+		sG.Set(0, 0, '5')
+		sG.Set(8, 0, '5')
+
+		Expect(sG.Solve()).NotTo(Succeed())
+		Expect(sG.CountSolutions(0)).To(Equal(0))
+	})
+
+	It("rejects values that are not allowed in the grid", func() {
+		sG, err := New(4, 2, 2)
+		Expect(err).To(BeNil())
+		sG.Set(0, 0, '9')
+
+		Expect(sG.Solve()).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("Cancellation", func() {
+	It("stops solving once the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		sG := parseGrid(9, 3, hardPuzzle)
+		Expect(sG.SolveContext(ctx)).To(MatchError(context.Canceled))
+		Expect(sG.Grid).To(Equal(parseGrid(9, 3, hardPuzzle).Grid))
+	})
+
+	It("stops every solver once the deadline is exceeded", func() {
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			start := time.Now()
+			// an empty 25x25 grid has far too many solutions to be counted
+			sG, err := New(25, 5, 5)
+			Expect(err).To(BeNil())
+			_, _, err = sG.CountSolutionsWith(ctx, s, 0)
+			cancel()
+
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		}
+	})
+
+	It("gives up after the given number of search nodes", func() {
+		for _, s := range []Solver{BacktrackingSolver{maxNodes: 100}, DLXSolver{maxNodes: 100}} {
+			sG, err := New(25, 5, 5)
+			Expect(err).To(BeNil())
+			stats, err := sG.SolveWith(context.Background(), s)
+
+			// the search stops without a solution, whatever the speed of the machine
+			Expect(err).To(MatchError(ErrNoSolution))
+			Expect(stats.Nodes).To(Equal(100))
+		}
+	})
+
+	It("does not report an error when the search completes in time", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		solutions, _, err := parseGrid(9, 3, hardPuzzle).FindSolutionsWith(ctx, DLXSolver{}, 0)
+		Expect(err).To(BeNil())
+		Expect(solutions).To(HaveLen(1))
+	})
+
+	It("stops generating once the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		sG, err := GenerateSudokuGridContext(ctx, 9, 3, 3)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(sG).To(BeNil())
+
+		sG, err = GenerateSudokuGrid(9, 3, 3)
+		Expect(err).To(BeNil())
+		Expect(sG.SetGridToLevelContext(ctx, "hard", WithUniqueSolution())).To(MatchError(context.Canceled))
+		Expect(isSolved(sG)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Solver registry", func() {
+	It("provides the built-in solvers", func() {
+		Expect(Solvers()).To(Equal([]string{"backtracking", "dlx"}))
+
+		s, err := GetSolver(DefaultSolver)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal(BacktrackingSolver{}))
+
+		s, err = GetSolver("dlx")
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal(DLXSolver{}))
+	})
+
+	It("returns an error for an unknown solver", func() {
+		s, err := GetSolver("unknown")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("backtracking, dlx"))
+		Expect(s).To(BeNil())
+	})
+
+	It("panics when a name is registered twice", func() {
+		Expect(func() { RegisterSolver("dlx", DLXSolver{}) }).To(Panic())
+	})
+
+	It("reports the search statistics", func() {
+		for _, name := range Solvers() {
+			s, err := GetSolver(name)
+			Expect(err).To(BeNil())
+
+			sG := parseGrid(9, 3, hardPuzzle)
+			stats, err := sG.SolveWith(context.Background(), s)
+			Expect(err).To(BeNil())
+			Expect(stats.Nodes).To(BeNumerically(">", 1))
+			Expect(stats.Backtracks).To(BeNumerically(">", 0))
+
+			// a puzzle with a single empty cell is solved without any guess
+			full := patternGrid(9, 3, 3)
+			full.Set(4, 4, EMPTY_CELL)
+			stats, err = full.SolveWith(context.Background(), s)
+			Expect(err).To(BeNil())
+			Expect(stats.Backtracks).To(Equal(0))
+		}
+	})
+})
+
+// sparseGivens keeps one given out of every n of the cells listed in row-major order, the others are emptied
+func sparseGivens(cells string, n int) string {
+	sparse := []rune(cells)
+	givens := 0
+	for i, c := range sparse {
+		if c == EMPTY_CELL {
+			continue
+		}
+		if givens%n != 0 {
+			sparse[i] = EMPTY_CELL
+		}
+		givens++
+	}
+	return string(sparse)
+}
+
+var benchmarkInputs = []struct {
+	name            string
+	size, partition int
+	cells           string
+	sparse          bool // too few givens for the plain backtracking of solve to find a solution in time
+}{
+	{"9x9", 9, 3, hardPuzzle, false},
+	{"16x16", 16, 4, puzzle16x16, false},
+	{"25x25", 25, 5, puzzle25x25, false},
+	{"16x16/sparse", 16, 4, sparseGivens(puzzle16x16, 3), true},
+	{"25x25/sparse", 25, 5, sparseGivens(puzzle25x25, 3), true},
+	{"16x16/empty", 16, 4, "", true},
+	{"25x25/empty", 25, 5, "", true},
+}
+
+// BenchmarkSolveReference measures the plain backtracking of solve, the reference the solvers are checked against
+func BenchmarkSolveReference(b *testing.B) {
+	for _, input := range benchmarkInputs {
+		if input.sparse {
+			continue
+		}
+		puzzle := parseGrid(input.size, input.partition, input.cells)
+		b.Run(input.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sG := puzzle.Clone()
+				if !sG.solve(sG.missingCells(), func() bool { return true }) {
+					b.Fatal("no solution found")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSolveBacktracking(b *testing.B) {
+	for _, input := range benchmarkInputs {
+		puzzle := parseGrid(input.size, input.partition, input.cells)
+		b.Run(input.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sG := puzzle.Clone()
+				if _, err := sG.SolveWith(context.Background(), BacktrackingSolver{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package sudoku
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,14 +18,26 @@ const (
 )
 
 type SudokuGrid struct {
-	Size            int      `json:"size"`
-	PartitionWidth  int      `json:"partitionWidth"`
-	PartitionHeight int      `json:"partitionHeight"`
-	Grid            [][]rune `json:"grid"`
+	Size            int          `json:"size"`
+	PartitionWidth  int          `json:"partitionWidth"`
+	PartitionHeight int          `json:"partitionHeight"`
+	Grid            [][]rune     `json:"grid"`
+	Symbols         string       `json:"symbols,omitempty"`      // values of the cells in order, DefaultSymbols if empty
+	PencilMarks     [][][]rune   `json:"pencilMarks,omitempty"`  // candidates kept by the player for each cell, optional
+	Variant         string       `json:"variant,omitempty"`      // rules of the puzzle, ClassicVariant if empty
+	Cages           []Cage       `json:"cages,omitempty"`        // cages of a Killer Sudoku, optional
+	Regions         [][]int      `json:"regions,omitempty"`      // subgrid of each cell of a jigsaw sudoku, optional
+	Constraints     Constraints  `json:"constraints,omitempty"`  // extra rules of the puzzle, optional
+	ExtraRegions    [][]Cell     `json:"extraRegions,omitempty"` // groups of Size cells also holding each symbol once, optional
+	Inequalities    []Inequality `json:"inequalities,omitempty"` // signs between adjacent cells of a comparison sudoku, optional
+	Clues           []Clue       `json:"clues,omitempty"`        // clues written outside of the grid, optional
+	Seed            *int64       `json:"seed,omitempty"`         // seed the grid was generated from, see WithSeed
 	rowsMap         []map[rune]bool
 	colsMap         []map[rune]bool
 	subGridMap      []map[rune]bool
+	diagonalsMap    [2]map[rune]bool // values on the main diagonal and on the anti-diagonal
 	allowedValues   []rune
+	rng             *rand.Rand // source of the random choices of the generator, see random
 }
 
 type coord struct {
@@ -33,10 +46,20 @@ type coord struct {
 
 // New Returns an empty sG.Size x sG.Size SudokuGrid
 func New(size, partitionWidth, partitionHeight int) (*SudokuGrid, error) {
+	return NewWithSymbols(size, partitionWidth, partitionHeight, "")
+}
+
+// NewWithSymbols is like New but the values of the cells are the given symbols, DefaultSymbols if empty. The
+// default symbols are left out of the Symbols of the grid, whether given or not.
+func NewWithSymbols(size, partitionWidth, partitionHeight int, symbols string) (*SudokuGrid, error) {
+	if err := validSize(size); err != nil {
+		return nil, err
+	}
 	sG := SudokuGrid{
 		Size:            size,
 		PartitionWidth:  partitionWidth,
 		PartitionHeight: partitionHeight,
+		Symbols:         customSymbols(symbols, size),
 	}
 
 	sG.Grid = make([][]rune, sG.Size)
@@ -55,13 +78,6 @@ func New(size, partitionWidth, partitionHeight int) (*SudokuGrid, error) {
 	return &sG, nil
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
 func (sG *SudokuGrid) initMetadata() {
 	sG.rowsMap = make([]map[rune]bool, sG.Size)
 	sG.colsMap = make([]map[rune]bool, sG.Size)
@@ -72,17 +88,17 @@ func (sG *SudokuGrid) initMetadata() {
 		sG.colsMap[i] = make(map[rune]bool)
 		sG.subGridMap[i] = make(map[rune]bool)
 	}
+	for i := range sG.diagonalsMap {
+		sG.diagonalsMap[i] = make(map[rune]bool)
+	}
 
-	// update the state of rowsMap, colsMap, subGridMap
+	// update the state of rowsMap, colsMap, subGridMap, diagonalsMap
 	for i := 0; i < sG.Size; i++ {
 		for j := 0; j < len(sG.Grid[i]); j++ {
 			sG.Set(i, j, sG.Grid[i][j])
 		}
 	}
-	sG.allowedValues = make([]rune, 0, max(sG.Size, sG.PartitionHeight*sG.PartitionWidth))
-	for val := '1'; val <= rune('0'+cap(sG.allowedValues)); val++ {
-		sG.allowedValues = append(sG.allowedValues, val)
-	}
+	sG.allowedValues = sG.symbols()
 }
 
 // Reset sets all the cells of the SudokuGrid to EMPTY_CELL value
@@ -90,33 +106,239 @@ func (sG *SudokuGrid) Reset() {
 	sG, _ = New(sG.Size, sG.PartitionWidth, sG.PartitionHeight)
 }
 
-// Solve solves the SudokuGrid in-place, returns an error if no solution exist
-func (sG *SudokuGrid) Solve() error {
-	missingCells := make([]coord, 0, sG.Size*sG.Size)
+// Clone returns a deep copy of the SudokuGrid
+func (sG *SudokuGrid) Clone() *SudokuGrid {
+	clone := SudokuGrid{
+		Size:            sG.Size,
+		PartitionWidth:  sG.PartitionWidth,
+		PartitionHeight: sG.PartitionHeight,
+		Symbols:         sG.Symbols,
+		Variant:         sG.Variant,
+		Constraints:     sG.Constraints.clone(),
+		Grid:            make([][]rune, len(sG.Grid)),
+	}
+	for i := range sG.Grid {
+		clone.Grid[i] = make([]rune, len(sG.Grid[i]))
+		copy(clone.Grid[i], sG.Grid[i])
+	}
+	if sG.Cages != nil {
+		clone.Cages = make([]Cage, len(sG.Cages))
+		for i, cage := range sG.Cages {
+			clone.Cages[i] = Cage{Sum: cage.Sum, Cells: make([]Cell, len(cage.Cells))}
+			copy(clone.Cages[i].Cells, cage.Cells)
+		}
+	}
+	if sG.Regions != nil {
+		clone.Regions = make([][]int, len(sG.Regions))
+		for i := range sG.Regions {
+			clone.Regions[i] = make([]int, len(sG.Regions[i]))
+			copy(clone.Regions[i], sG.Regions[i])
+		}
+	}
+	if sG.ExtraRegions != nil {
+		clone.ExtraRegions = make([][]Cell, len(sG.ExtraRegions))
+		for i, region := range sG.ExtraRegions {
+			clone.ExtraRegions[i] = make([]Cell, len(region))
+			copy(clone.ExtraRegions[i], region)
+		}
+	}
+	if sG.Inequalities != nil {
+		clone.Inequalities = make([]Inequality, len(sG.Inequalities))
+		copy(clone.Inequalities, sG.Inequalities)
+	}
+	if sG.Clues != nil {
+		clone.Clues = make([]Clue, len(sG.Clues))
+		copy(clone.Clues, sG.Clues)
+	}
+	if sG.Seed != nil {
+		seed := *sG.Seed
+		clone.Seed = &seed
+	}
+	if sG.PencilMarks != nil {
+		clone.PencilMarks = make([][][]rune, len(sG.PencilMarks))
+		for i := range sG.PencilMarks {
+			clone.PencilMarks[i] = make([][]rune, len(sG.PencilMarks[i]))
+			for j, marks := range sG.PencilMarks[i] {
+				if marks != nil {
+					clone.PencilMarks[i][j] = make([]rune, len(marks))
+					copy(clone.PencilMarks[i][j], marks)
+				}
+			}
+		}
+	}
+	clone.initMetadata()
+	return &clone
+}
+
+// missingCells returns the coordinates of all the empty cells in row-major order
+func (sG *SudokuGrid) missingCells() []coord {
+	cells := make([]coord, 0, sG.Size*sG.Size)
 
 	for i := 0; i < sG.Size; i++ {
 		for j := 0; j < len(sG.Grid[i]); j++ {
 			if sG.Grid[i][j] == EMPTY_CELL {
-				missingCells = append(missingCells, coord{x: i, y: j})
+				cells = append(cells, coord{x: i, y: j})
 			}
 		}
 	}
+	return cells
+}
 
-	if !sG.solve(missingCells) {
-		return errors.New("no solution exists")
+// filledCells returns the coordinates of all the non-empty cells in row-major order
+func (sG *SudokuGrid) filledCells() []coord {
+	cells := make([]coord, 0, sG.Size*sG.Size)
+	for i := 0; i < sG.Size; i++ {
+		for j := 0; j < len(sG.Grid[i]); j++ {
+			if sG.Grid[i][j] != EMPTY_CELL {
+				cells = append(cells, coord{x: i, y: j})
+			}
+		}
 	}
-	return nil
+	return cells
+}
+
+// Solve solves the SudokuGrid in-place, returns an error if no solution exist
+func (sG *SudokuGrid) Solve() error {
+	return sG.SolveContext(context.Background())
+}
+
+// SolveContext is like Solve but gives up with the context error once ctx is done
+func (sG *SudokuGrid) SolveContext(ctx context.Context) error {
+	_, err := sG.SolveWith(ctx, BacktrackingSolver{})
+	return err
+}
+
+// SolveWith solves the SudokuGrid in-place using the given Solver, returns the search statistics
+// and an error if no solution exist or ctx is done before a solution is found
+func (sG *SudokuGrid) SolveWith(ctx context.Context, s Solver) (Stats, error) {
+	// stop at the first solution found, leaving it in the grid
+	found, stats, err := sG.search(ctx, s, func() bool { return true })
+	if err != nil {
+		return stats, err
+	}
+	if err := contextError(ctx); err != nil && !found {
+		return stats, err
+	}
+	if !found {
+		return stats, ErrNoSolution
+	}
+	return stats, nil
 }
 
-func (sG *SudokuGrid) solve(cells []coord) bool {
+// search runs the Solver on the SudokuGrid. The solvers of the package are run on its layout, so that a grid they
+// cannot lay out is reported by the error of gridLayout rather than as a grid without solution.
+func (sG *SudokuGrid) search(ctx context.Context, s Solver, found func() bool) (bool, Stats, error) {
+	ls, ok := s.(layoutSearcher)
+	if !ok {
+		stopped, stats := s.Search(ctx, sG, found)
+		return stopped, stats, nil
+	}
+	l, values, err := gridLayout(sG)
+	if err != nil {
+		return false, Stats{}, err
+	}
+	stopped, stats := ls.searchLayout(ctx, l, values, func(solution []int) bool {
+		l.apply(sG, solution)
+		return found()
+	})
+	return stopped, stats, nil
+}
+
+// FindSolutions returns up to limit distinct solutions of the SudokuGrid, the SudokuGrid itself is left unchanged.
+// If limit is not positive, all the solutions are returned.
+func (sG *SudokuGrid) FindSolutions(limit int) []*SudokuGrid {
+	solutions, _, _ := sG.FindSolutionsWith(context.Background(), BacktrackingSolver{}, limit)
+	return solutions
+}
+
+// FindSolutionsWith is like FindSolutions but uses the given Solver, and also returns the search statistics.
+// If ctx is done before the search is over, the solutions found so far are returned along with the context error.
+func (sG *SudokuGrid) FindSolutionsWith(ctx context.Context, s Solver, limit int) ([]*SudokuGrid, Stats, error) {
+	solutions := []*SudokuGrid{}
+
+	work := sG.Clone()
+	stopped, stats, err := work.search(ctx, s, func() bool {
+		solutions = append(solutions, work.Clone())
+		return limit > 0 && len(solutions) >= limit
+	})
+	if err != nil {
+		return nil, stats, err
+	}
+	if err := contextError(ctx); err != nil && !stopped {
+		return solutions, stats, err
+	}
+	return solutions, stats, nil
+}
+
+// CountSolutions returns the number of solutions of the SudokuGrid, counting stops once limit is reached.
+// If limit is not positive, all the solutions are counted.
+func (sG *SudokuGrid) CountSolutions(limit int) int {
+	count, _, _ := sG.CountSolutionsWith(context.Background(), BacktrackingSolver{}, limit)
+	return count
+}
+
+// CountSolutionsWith is like CountSolutions but uses the given Solver, and also returns the search statistics.
+// If ctx is done before the search is over, the count so far is returned along with the context error.
+func (sG *SudokuGrid) CountSolutionsWith(ctx context.Context, s Solver, limit int) (int, Stats, error) {
+	count := 0
+
+	work := sG.Clone()
+	stopped, stats, err := work.search(ctx, s, func() bool {
+		count++
+		return limit > 0 && count >= limit
+	})
+	if err != nil {
+		return 0, stats, err
+	}
+	if err := contextError(ctx); err != nil && !stopped {
+		return count, stats, err
+	}
+	return count, stats, nil
+}
+
+// IsUnique returns true if the SudokuGrid has exactly one solution
+func (sG *SudokuGrid) IsUnique() bool {
+	return sG.CountSolutions(2) == 1
+}
+
+// solutionsWithin returns up to 2 solutions of the SudokuGrid found by BacktrackingSolver within
+// uniquenessCheckNodes search nodes, complete is false if the search was given up before it was over. Unlike a
+// timeout, the budget gives the same answer on every machine so that seeded puzzles can be generated again.
+// The error is only set once ctx is done.
+func (sG *SudokuGrid) solutionsWithin(ctx context.Context) (solutions []*SudokuGrid, complete bool, err error) {
+	work := sG.Clone()
+	stopped, stats, err := work.search(ctx, BacktrackingSolver{maxNodes: uniquenessCheckNodes}, func() bool {
+		solutions = append(solutions, work.Clone())
+		return len(solutions) >= 2
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, false, err
+	}
+	return solutions, stopped || stats.Nodes < uniquenessCheckNodes, nil
+}
+
+// uniqueWithin returns true if BacktrackingSolver proves within uniquenessCheckNodes search nodes that the
+// SudokuGrid has exactly one solution, a grid whose proof takes longer is treated as ambiguous
+func (sG *SudokuGrid) uniqueWithin(ctx context.Context) (bool, error) {
+	solutions, complete, err := sG.solutionsWithin(ctx)
+	return complete && len(solutions) == 1, err
+}
+
+// solve fills the given cells by plain backtracking in row-major order, calling found every time the grid is complete.
+// The search stops as soon as found returns true, in which case the solution is left in the grid and solve returns true.
+// It is much slower than BacktrackingSolver on large grids and is kept as a reference implementation.
+func (sG *SudokuGrid) solve(cells []coord, found func() bool) bool {
 	if len(cells) == 0 {
-		return true
+		return found()
 	}
 
 	x := cells[0].x
 	y := cells[0].y
 
-	for val := '1'; val <= rune('0'+sG.Size); val++ {
+	for _, val := range sG.symbols() {
 		if sG.canSet(x, y, val) {
 			oldValue := sG.Grid[x][y]
 
@@ -124,7 +346,7 @@ func (sG *SudokuGrid) solve(cells []coord) bool {
 			sG.Set(x, y, val)
 
 			// continue backtracking on the next cell
-			if sG.solve(cells[1:]) {
+			if sG.solve(cells[1:], found) {
 				return true
 			}
 
@@ -138,7 +360,7 @@ func (sG *SudokuGrid) solve(cells []coord) bool {
 // isValidIndex returns true if the coordinates (x, y) represent a valid cell, and false otherwise
 func (sG *SudokuGrid) isValidIndex(x, y int) error {
 	if x < 0 || x >= sG.Size || y < 0 || y >= sG.Size {
-		return fmt.Errorf("cell coordinates out of bounds (%d, %d)", x, y)
+		return &OutOfBoundsError{Row: x, Col: y}
 	}
 	return nil
 }
@@ -164,9 +386,14 @@ func (sG *SudokuGrid) Set(x, y int, val rune) error {
 }
 
 // GetSubgridIndex returns the index of the partition containing the cell with coordinates (x, y) in the partitions grid - subgrid -.
+// Subgrids are PartitionHeight rows high and PartitionWidth columns wide, they are numbered in row-major order.
+// The subgrids of a jigsaw sudoku are its Regions.
 func (sG *SudokuGrid) GetSubgridIndex(x, y int) int {
+	if sG.Regions != nil {
+		return sG.Regions[x][y]
+	}
 	// floor(x/Height) * ROW_SIZE + floor(y/W)
-	// ROW_SIZE is the size of the compressed matrix where each cell represents a subgrid
+	// ROW_SIZE is the number of subgrids in a row of subgrids, Size / PartitionWidth = PartitionHeight
 	compressedMatrixWidth := sG.Size / sG.PartitionWidth
 	return (x/sG.PartitionHeight)*(compressedMatrixWidth) + y/sG.PartitionWidth
 }
@@ -178,19 +405,48 @@ func (sG *SudokuGrid) updateCount(x, y int, newValue rune) {
 	sG.rowsMap[x][oldValue] = false
 	sG.colsMap[y][oldValue] = false
 	sG.subGridMap[sG.GetSubgridIndex(x, y)][oldValue] = false
+	for _, d := range sG.diagonals(x, y) {
+		sG.diagonalsMap[d][oldValue] = false
+	}
 
 	// increment row, col, subgrid count of the newValue
 	sG.rowsMap[x][newValue] = true
 	sG.colsMap[y][newValue] = true
 	sG.subGridMap[sG.GetSubgridIndex(x, y)][newValue] = true
+	for _, d := range sG.diagonals(x, y) {
+		sG.diagonalsMap[d][newValue] = true
+	}
 }
 
-// canSet returns true if the given value doesn't exist in the same row (x), column (y), or subgrid
+// diagonals returns the indexes of the diagonals the cell (x, y) lies on: 0 for the main diagonal, 1 for the anti-diagonal
+func (sG *SudokuGrid) diagonals(x, y int) []int {
+	var res []int
+	if x == y {
+		res = append(res, 0)
+	}
+	if x+y == sG.Size-1 {
+		res = append(res, 1)
+	}
+	return res
+}
+
+// canSet returns true if the given value doesn't exist in the same row (x), column (y), subgrid or extra region,
+// nor in the same diagonal for the DiagonalVariant, and the constraints of the grid allow it
 func (sG *SudokuGrid) canSet(x, y int, val rune) bool {
 	if err := sG.isValidIndex(x, y); err != nil {
 		return false
 	}
-	return !(sG.rowsMap[x][val] || sG.colsMap[y][val] || sG.subGridMap[sG.GetSubgridIndex(x, y)][val])
+	if sG.rowsMap[x][val] || sG.colsMap[y][val] || sG.subGridMap[sG.GetSubgridIndex(x, y)][val] {
+		return false
+	}
+	if sG.Variant == DiagonalVariant {
+		for _, d := range sG.diagonals(x, y) {
+			if sG.diagonalsMap[d][val] {
+				return false
+			}
+		}
+	}
+	return sG.extraRegionsAllow(x, y, val) && sG.constraintsAllow(x, y, val)
 }
 
 func (sG *SudokuGrid) MarshalJSON() ([]byte, error) {
@@ -214,53 +470,151 @@ func (sG *SudokuGrid) UnmarshalJSON(data []byte) error {
 }
 
 // GenerateSudokuGrid returns a SudokuGrid with the given dimensions
-func GenerateSudokuGrid(size, partitionWidth, partitionHeight int) (*SudokuGrid, error) {
-	sG, err := New(size, partitionWidth, partitionHeight)
+func GenerateSudokuGrid(size, partitionWidth, partitionHeight int, opts ...GeneratorOption) (*SudokuGrid, error) {
+	return GenerateSudokuGridContext(context.Background(), size, partitionWidth, partitionHeight, opts...)
+}
+
+// GenerateSudokuGridContext is like GenerateSudokuGrid but gives up with the context error once ctx is done
+func GenerateSudokuGridContext(ctx context.Context, size, partitionWidth, partitionHeight int, opts ...GeneratorOption) (*SudokuGrid, error) {
+	o := newGeneratorOptions(opts)
+	if o.jigsaw {
+		if o.hyper {
+			return nil, fmt.Errorf("%w: the windows of a hyper sudoku need partitions, they cannot be combined with jigsaw regions", ErrInvalidRegion)
+		}
+		return generateJigsaw(ctx, size, o)
+	}
+
+	sG, err := NewWithSymbols(size, partitionWidth, partitionHeight, o.symbols)
 	if err != nil {
 		return nil, err
 	}
+	sG.Variant = o.variant
+	sG.Constraints = o.constraints
+	sG.ExtraRegions = o.extraRegions
+	sG.Seed, sG.rng = o.seed, o.rand
+	if o.hyper {
+		sG.ExtraRegions = append(HyperRegions(size, partitionWidth, partitionHeight), sG.ExtraRegions...)
+	}
+	if err := sG.Valid(); err != nil {
+		return nil, err
+	}
 
 	// shuffling the allowed values => random puzzle generation
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(sG.allowedValues), func(i, j int) { sG.allowedValues[i], sG.allowedValues[j] = sG.allowedValues[j], sG.allowedValues[i] })
+	sG.random().Shuffle(len(sG.allowedValues), func(i, j int) { sG.allowedValues[i], sG.allowedValues[j] = sG.allowedValues[j], sG.allowedValues[i] })
 
 	log.Debugf("generating sudoku grid using the allowed values: %v\n", sG.allowedValues)
 
-	if generateSudokuGrid(sG, 0, 0) {
-		return sG, nil
+	// the subgrids on the diagonal share no row nor column, they are filled independently at random
+	// so that the grids generated are not all relabelings of the same one, the solver completes the rest.
+	// With small subgrids the random ones may not fit together and on large grids some fillings take the solver
+	// very long to complete, such attempts are given up and retried, filling the first subgrid only always works.
+	// The constraints and the extra regions may rule out random fillings, one subgrid less is filled at each attempt
+	// down to the empty grid, and the constraints are left to the backtracking solver which prunes the candidates
+	// they rule out.
+	diagonal := sG.Size / sG.PartitionHeight
+	if stacks := sG.Size / sG.PartitionWidth; stacks < diagonal {
+		diagonal = stacks
+	}
+	for attempt := 0; attempt <= generationRestarts; attempt++ {
+		subgrids, maxNodes := diagonal, generationRestartNodes
+		if len(sG.Constraints) > 0 || len(sG.ExtraRegions) > 0 {
+			subgrids = diagonal - attempt
+			if subgrids < 0 || attempt == generationRestarts {
+				subgrids = 0
+			}
+		}
+		if attempt == generationRestarts {
+			maxNodes = 0
+			if len(sG.Constraints) == 0 {
+				subgrids = 1
+			}
+		}
+		var solver Solver = DLXSolver{maxNodes: maxNodes}
+		if len(sG.Constraints) > 0 {
+			solver = BacktrackingSolver{maxNodes: maxNodes}
+		}
+		err = ErrNoSolution
+		if fillDiagonalSubgrids(sG, subgrids) {
+			_, err = sG.SolveWith(ctx, solver)
+		}
+		if err == nil {
+			return sG, nil
+		}
+		if err := contextError(ctx); err != nil {
+			return nil, err
+		}
+		for _, c := range sG.filledCells() {
+			sG.Set(c.x, c.y, EMPTY_CELL)
+		}
 	}
 
 	return nil, errors.New("could not generate a valid sudoku grid")
 }
 
-func generateSudokuGrid(sG *SudokuGrid, i, j int) bool {
-	// surpasses the last row or last cell (sG.Size - 1, sG.Size - 1)
-	if i >= sG.Size {
-		return true
-	}
-	for _, val := range sG.allowedValues {
-		if sG.canSet(i, j, val) {
-			// try this value
-			sG.Set(i, j, val)
+const (
+	// generationRestarts is the number of attempts at completing random diagonal subgrids before filling one only
+	generationRestarts = 5
+	// generationRestartNodes is the number of search nodes given to the solver to complete the random diagonal
+	// subgrids before the attempt is given up, about a second on a 16x16 grid. Counting nodes rather than time keeps
+	// the grids generated from a seed the same on every machine.
+	generationRestartNodes = 200000
+	// gradingAttemptNodes is the number of search nodes given to an attempt at removing clues down to a graded
+	// level, a 9x9 grid needs less than 50000. Grading the puzzle counts as gradeNodes nodes per cell, about the time
+	// it takes.
+	gradingAttemptNodes = 2000000
+	gradeNodes          = 8
+	// gradingNodes is the number of search nodes given to all the attempts at reaching a graded level, a few seconds
+	// on the largest grids
+	gradingNodes = 2500000
+	// uniquenessCheckNodes is the number of search nodes given to the solver to prove that a puzzle being generated
+	// without givens to start from, such as a comparison sudoku, has a unique solution, about 100ms on a 9x9 grid
+	uniquenessCheckNodes = 5000
+)
 
-			// determine our next cell
-			newI, newJ := i, j+1
-			if j+1 >= sG.Size {
-				newI = i + 1
-				newJ = 0
+// fillDiagonalSubgrids sets the cells of the first n subgrids on the diagonal of the grid to random permutations
+// of the allowed values. It returns false if a cell is left without a value satisfying the rules of the variant.
+func fillDiagonalSubgrids(sG *SudokuGrid, n int) bool {
+	values := make([]rune, sG.Size)
+	for b := 0; b < n; b++ {
+		copy(values, sG.allowedValues)
+		sG.random().Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+		left := values
+		for x := 0; x < sG.Size; x++ {
+			for y := 0; y < sG.Size; y++ {
+				if sG.GetSubgridIndex(x, y) != sG.diagonalSubgrid(b) {
+					continue
+				}
+				k := 0
+				for k < len(left) && !sG.canSet(x, y, left[k]) {
+					k++
+				}
+				if k == len(left) {
+					return false
+				}
+				sG.Set(x, y, left[k])
+				left = append(left[:k], left[k+1:]...)
 			}
-
-			// continue backtracking on the next cell
-			if generateSudokuGrid(sG, newI, newJ) {
-				return true
-			}
-
-			// it didnt work, reset the old value
-			sG.Set(i, j, EMPTY_CELL)
 		}
 	}
+	return true
+}
 
-	return false
+// diagonalSubgrid returns the index of the b-th subgrid on the diagonal of the grid, the region b of a jigsaw sudoku
+func (sG *SudokuGrid) diagonalSubgrid(b int) int {
+	if sG.Regions != nil {
+		return b
+	}
+	return sG.GetSubgridIndex(b*sG.PartitionHeight, b*sG.PartitionWidth)
+}
+
+// done reports whether ctx is done without blocking
+func done(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
 }
 
 func getLevelThreshold(level string) (float64, error) {
@@ -279,15 +633,94 @@ func getLevelThreshold(level string) (float64, error) {
 	return 0, errors.New("invalid level: must be one of the supported levels (easy, medium, hard, extreme, robot)")
 }
 
+// GeneratorOption customizes how puzzles are generated
+type GeneratorOption func(*generatorOptions)
+
+type generatorOptions struct {
+	unique       bool
+	graded       bool
+	maxAttempts  int
+	symbols      string
+	variant      string
+	jigsaw       bool
+	hyper        bool
+	extraRegions [][]Cell
+	constraints  Constraints
+	rand         *rand.Rand
+	seed         *int64
+}
+
+// WithUniqueSolution only removes a clue if the puzzle still has exactly one solution afterwards
+func WithUniqueSolution() GeneratorOption {
+	return func(o *generatorOptions) {
+		o.unique = true
+	}
+}
+
+// DefaultGradingAttempts is a sensible number of attempts for WithGradedDifficulty
+const DefaultGradingAttempts = 20
+
+// WithGradedDifficulty keeps removing clues until Grade rates the puzzle at the requested level, rather than
+// only emptying the fraction of the cells matching the level. The solution is kept unique, and up to
+// maxAttempts removal orders are tried before giving up, fewer on large grids whose uniqueness takes long to prove.
+func WithGradedDifficulty(maxAttempts int) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.graded = true
+		o.maxAttempts = maxAttempts
+	}
+}
+
+// WithRand makes the random choices of the generator with r instead of a source seeded from the clock, the same
+// state of r giving the same puzzle. The grid keeps using r when it is turned into a puzzle afterwards, by
+// SetGridToLevel, SetGridToKiller, SetGridToInequalities or SetGridToClues.
+func WithRand(r *rand.Rand) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.rand = r
+		o.seed = nil
+	}
+}
+
+// WithSeed makes the random choices of the generator from the given seed, see WithRand, which is recorded in the
+// Seed of the grid: the same seed and options give the same puzzle byte for byte, on any machine.
+func WithSeed(seed int64) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.rand = rand.New(rand.NewSource(seed))
+		o.seed = &seed
+	}
+}
+
+func newGeneratorOptions(opts []GeneratorOption) *generatorOptions {
+	o := &generatorOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 // SetGridTolevel adds empty cells to match the desired difficulty level
-func (sG *SudokuGrid) SetGridToLevel(level string) error {
+func (sG *SudokuGrid) SetGridToLevel(level string, opts ...GeneratorOption) error {
+	return sG.SetGridToLevelContext(context.Background(), level, opts...)
+}
+
+// SetGridToLevelContext is like SetGridToLevel but gives up with the context error once ctx is done
+func (sG *SudokuGrid) SetGridToLevelContext(ctx context.Context, level string, opts ...GeneratorOption) error {
 	threshold, err := getLevelThreshold(level)
 	if err != nil {
 		return err
 	}
+	o := newGeneratorOptions(opts)
+	if o.rand != nil {
+		sG.rng = o.rand
+	}
+	if o.graded {
+		return sG.removeCluesGraded(ctx, level, threshold, o.maxAttempts)
+	}
+	if o.unique {
+		return sG.removeCluesUnique(ctx, threshold)
+	}
 	for i := 0; i < sG.Size; i++ {
 		for j := 0; j < len(sG.Grid[i]); j++ {
-			if rand.Float64() < threshold {
+			if sG.random().Float64() < threshold {
 				sG.Set(i, j, EMPTY_CELL)
 			}
 		}
@@ -295,32 +728,389 @@ func (sG *SudokuGrid) SetGridToLevel(level string) error {
 	return nil
 }
 
+// removeCluesUnique empties up to threshold * sG.Size^2 cells one at a time in a random order,
+// a removal is only kept if the puzzle still has a unique solution.
+func (sG *SudokuGrid) removeCluesUnique(ctx context.Context, threshold float64) error {
+	target := int(threshold * float64(sG.Size*sG.Size))
+
+	cells := sG.filledCells()
+	sG.random().Shuffle(len(cells), func(i, j int) { cells[i], cells[j] = cells[j], cells[i] })
+
+	removed := 0
+	for _, c := range cells {
+		if removed >= target {
+			break
+		}
+		oldValue := sG.Grid[c.x][c.y]
+		sG.Set(c.x, c.y, EMPTY_CELL)
+		count, _, err := sG.CountSolutionsWith(ctx, BacktrackingSolver{}, 2)
+		if err != nil {
+			sG.Set(c.x, c.y, oldValue)
+			return err
+		}
+		if count != 1 {
+			// the puzzle became ambiguous, put the clue back
+			sG.Set(c.x, c.y, oldValue)
+			continue
+		}
+		removed++
+	}
+	return nil
+}
+
+// removeCluesGraded empties cells one at a time in a random order until threshold * sG.Size^2 cells are empty
+// and the puzzle is graded at the given level. A removal is only kept if the solution stays unique and the grade
+// does not go past the level. If no clue can be removed anymore before the puzzle is hard enough, the removed clues
+// are put back and another order is tried. Each attempt is given gradingAttemptNodes search nodes to prove the
+// removals keep the solution unique and grade the puzzle, and the attempts stop once gradingNodes nodes are used in
+// all, so that large grids give up in a bounded time whatever the speed of the machine. After maxAttempts failures or once the nodes are used up, the SudokuGrid holds the
+// puzzle whose grade was the closest to the level and a LevelError describing it is returned.
+func (sG *SudokuGrid) removeCluesGraded(ctx context.Context, level string, threshold float64, maxAttempts int) error {
+	low, high, err := gradeBand(level)
+	if err != nil {
+		return err
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	solution := sG.Clone()
+	var closest *SudokuGrid
+	var closestGrade *Difficulty
+	attempt, nodes := 0, 0
+	for ; attempt < maxAttempts && nodes < gradingNodes; attempt++ {
+		if attempt > 0 {
+			sG.copyFrom(solution)
+		}
+		maxNodes := gradingAttemptNodes
+		if left := gradingNodes - nodes; left < maxNodes {
+			maxNodes = left
+		}
+		grade, used, err := sG.removeCluesWithin(ctx, int(threshold*float64(sG.Size*sG.Size)), low, high, maxNodes)
+		if err != nil {
+			sG.copyFrom(solution)
+			return err
+		}
+		nodes += used
+		if grade.Score >= low {
+			return nil
+		}
+		if closestGrade == nil || grade.Score > closestGrade.Score {
+			closest, closestGrade = sG.Clone(), grade
+		}
+	}
+	sG.copyFrom(closest)
+	return &LevelError{Level: level, Attempts: attempt, Closest: closestGrade}
+}
+
+// removeCluesWithin empties cells one at a time in a random order, and returns the grade of the puzzle
+// once target cells are empty and its score reaches low, no clue can be removed anymore or maxNodes search nodes
+// are used, along with the number of nodes used, grading included. A removal is undone if the puzzle becomes ambiguous, is not proven
+// unique within the nodes left or its score reaches high.
+func (sG *SudokuGrid) removeCluesWithin(ctx context.Context, target, low, high, maxNodes int) (*Difficulty, int, error) {
+	cells := sG.filledCells()
+	sG.random().Shuffle(len(cells), func(i, j int) { cells[i], cells[j] = cells[j], cells[i] })
+
+	grade, err := GradeContext(ctx, sG)
+	if err != nil {
+		return nil, 0, err
+	}
+	gradeCost := gradeNodes * sG.Size * sG.Size
+	removed, nodes := len(sG.missingCells()), gradeCost
+	for _, c := range cells {
+		if (removed >= target && grade.Score >= low) || nodes >= maxNodes {
+			break
+		}
+		oldValue := sG.Grid[c.x][c.y]
+		sG.Set(c.x, c.y, EMPTY_CELL)
+		count, stats, err := sG.CountSolutionsWith(ctx, DLXSolver{maxNodes: maxNodes - nodes}, 2)
+		if err != nil {
+			return nil, nodes, err
+		}
+		complete := stats.Nodes < maxNodes-nodes
+		nodes += stats.Nodes
+		if count != 1 || !complete {
+			sG.Set(c.x, c.y, oldValue)
+			continue
+		}
+		next, err := GradeContext(ctx, sG)
+		if err != nil {
+			return nil, nodes, err
+		}
+		nodes += gradeCost
+		if next.Score >= high {
+			sG.Set(c.x, c.y, oldValue)
+			continue
+		}
+		grade = next
+		removed++
+	}
+	return grade, nodes, nil
+}
+
+// random returns the source of the random choices made generating the SudokuGrid, one seeded from the clock unless
+// a generator option gave one, see WithRand
+func (sG *SudokuGrid) random() *rand.Rand {
+	if sG.rng == nil {
+		sG.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return sG.rng
+}
+
+// copyFrom sets every cell of the SudokuGrid to the value of the same cell in other, which has the same dimensions
+func (sG *SudokuGrid) copyFrom(other *SudokuGrid) {
+	for i := range other.Grid {
+		for j, v := range other.Grid[i] {
+			sG.Set(i, j, v)
+		}
+	}
+}
+
 // ToStringPrettify returns a formatted string representation of the SudokuGrid
 func (sG *SudokuGrid) ToStringPrettify() string {
+	if len(sG.Cages) > 0 {
+		return sG.toStringKiller()
+	}
+	if sG.Regions != nil {
+		return sG.toStringJigsaw()
+	}
+	if len(sG.Inequalities) > 0 || len(sG.Clues) > 0 {
+		// the signs are drawn between the cells and the clues around them, within the outlines of the subgrids
+		group := make([]int, sG.Size*sG.Size)
+		for cell := range group {
+			group[cell] = sG.GetSubgridIndex(cell/sG.Size, cell%sG.Size)
+		}
+		return sG.toStringOutlined(group, nil, nil)
+	}
+	// each cell takes 3 characters, plus a separator between two subgrids of a row.
+	// The cells of the extra regions are shaded by brackets around their value.
+	shaded := sG.shaded()
+	width := sG.Size*3 + sG.Size/sG.PartitionWidth - 1
 	var res strings.Builder
-	res.Grow(sG.Size * (2*sG.Size + 1))
+	res.Grow((sG.Size + sG.PartitionWidth) * (width + 1))
 	for i := 0; i < sG.Size; i++ {
 		if i > 0 && i%sG.PartitionHeight == 0 {
-			fmt.Fprintf(&res, "%s\n", strings.Repeat("-", sG.Size*3+2))
+			fmt.Fprintf(&res, "%s\n", strings.Repeat("-", width))
 		}
 		for j := 0; j < len(sG.Grid[i]); j++ {
 			if j > 0 && j%sG.PartitionWidth == 0 {
 				fmt.Fprintf(&res, "|")
 			}
-			fmt.Fprintf(&res, "%2c ", sG.Grid[i][j])
+			if shaded != nil && shaded[i*sG.Size+j] {
+				fmt.Fprintf(&res, "[%c]", sG.Grid[i][j])
+			} else {
+				fmt.Fprintf(&res, "%2c ", sG.Grid[i][j])
+			}
 		}
 		fmt.Fprintf(&res, "\n")
 	}
 	return res.String()
 }
 
-// Valid returns all the errors if the SudokuGrid isn't valid, nil otherwise
+// toStringOutlined draws the SudokuGrid cell by cell, the cells of different groups being separated by outlines.
+// group holds the group of each cell in row-major order, dotted other groups outlined by dots, nil if none, and
+// labels the text written before the value of some cells. The cells of the extra regions are shaded, the signs of
+// the inequalities drawn between their cells and the clues around the grid.
+func (sG *SudokuGrid) toStringOutlined(group, dotted []int, labels map[int]string) string {
+	o := &outline{rows: sG.Size, cols: sG.Size, group: group, dotted: dotted, labels: labels, shaded: sG.shaded()}
+	for i := range sG.Grid {
+		o.cells = append(o.cells, sG.Grid[i]...)
+	}
+	o.left, o.above = sG.signs()
+	o.clues = sG.clueLabels()
+	return o.String()
+}
+
+// outline is a rows x cols canvas of cells in row-major order, the cells of different groups being separated by
+// outlines. A cell set to 0 is a hole of the canvas: it is left blank and only outlined next to the other cells.
+type outline struct {
+	rows, cols int
+	cells      []rune
+	group      []int
+	dotted     []int                 // other groups of the cells, outlined by dots where group is not, nil if none
+	labels     map[int]string        // text written before the value of some cells
+	shaded     []bool                // the values of the shaded cells are written between brackets, nil if no cell is
+	left       map[int]rune          // sign written on the left side of some cells, instead of the outline
+	above      map[int]rune          // sign written above the value of some cells, over the outline
+	clues      map[string][][]string // text written around the canvas by side, in layers starting from the canvas
+}
+
+func (o *outline) String() string {
+	rows, cols, cells, group := o.rows, o.cols, o.cells, o.group
+	digits := 1
+	for _, label := range o.labels {
+		if len(label) > digits {
+			digits = len(label)
+		}
+	}
+
+	// outside tells if (x, y) is out of the canvas or a hole
+	outside := func(x, y int) bool {
+		return x < 0 || y < 0 || x >= rows || y >= cols || cells[x*cols+y] == 0
+	}
+	// differ tells if the cells (x1, y1) and (x2, y2) are separated by an outline, cells out of the canvas included
+	differ := func(x1, y1, x2, y2 int) bool {
+		out1, out2 := outside(x1, y1), outside(x2, y2)
+		if out1 || out2 {
+			return out1 != out2
+		}
+		return group[x1*cols+y1] != group[x2*cols+y2]
+	}
+	// dots tells if the cells (x1, y1) and (x2, y2) are in different dotted groups, cells out of the canvas excluded
+	dots := func(x1, y1, x2, y2 int) bool {
+		if o.dotted == nil || outside(x1, y1) || outside(x2, y2) {
+			return false
+		}
+		return o.dotted[x1*cols+y1] != o.dotted[x2*cols+y2]
+	}
+
+	// each cell is the label, a space and the value, surrounded by the outlines, the brackets of the shaded cells
+	// taking one more character
+	width := digits + 2
+	if o.shaded != nil {
+		width++
+	}
+	// the layers of clues on the left and on the right of the rows are written in columns as wide as their
+	// longest clue, the first one next to the canvas, those above and below the columns end right over or under
+	// the values
+	layerWidths := map[string][]int{}
+	margin := 0
+	for _, side := range []string{SideLeft, SideRight} {
+		for _, layer := range o.clues[side] {
+			w := 0
+			for _, clue := range layer {
+				if len(clue) > w {
+					w = len(clue)
+				}
+			}
+			layerWidths[side] = append(layerWidths[side], w)
+			if side == SideLeft {
+				margin += w + 1
+			}
+		}
+	}
+	valueAt := digits + 2
+	if o.shaded != nil {
+		valueAt++
+	}
+	columns := func(clues []string) string {
+		var line strings.Builder
+		line.WriteString(strings.Repeat(" ", margin))
+		for y, clue := range clues {
+			if clue == "" {
+				continue
+			}
+			at := margin + y*(width+1) + valueAt - len(clue) + 1
+			if at <= line.Len() {
+				at = line.Len() + 1
+			}
+			line.WriteString(strings.Repeat(" ", at-line.Len()))
+			line.WriteString(clue)
+		}
+		return strings.TrimRight(line.String(), " ") + "\n"
+	}
+
+	var res strings.Builder
+	res.Grow((2*rows + 1 + len(o.clues[SideTop]) + len(o.clues[SideBottom])) * (cols*(width+1) + 2*margin + 2))
+	for i := len(o.clues[SideTop]) - 1; i >= 0; i-- {
+		res.WriteString(columns(o.clues[SideTop][i]))
+	}
+	for x := 0; x <= rows; x++ {
+		var line strings.Builder
+		line.WriteString(strings.Repeat(" ", margin))
+		for y := 0; y <= cols; y++ {
+			// a corner is drawn if any of the 4 outlines meeting there is drawn
+			if differ(x-1, y-1, x-1, y) || differ(x, y-1, x, y) || differ(x-1, y-1, x, y-1) || differ(x-1, y, x, y) {
+				line.WriteByte('+')
+			} else if dots(x-1, y-1, x-1, y) || dots(x, y-1, x, y) || dots(x-1, y-1, x, y-1) || dots(x-1, y, x, y) {
+				line.WriteByte('.')
+			} else {
+				line.WriteByte(' ')
+			}
+			if y == cols {
+				break
+			}
+			fill := " "
+			if differ(x-1, y, x, y) {
+				fill = "-"
+			} else if dots(x-1, y, x, y) {
+				fill = "."
+			}
+			if sign, ok := o.above[x*cols+y]; ok {
+				// the sign stands right above the value
+				fmt.Fprintf(&line, "%s%c%s", strings.Repeat(fill, digits+1), sign, strings.Repeat(fill, width-digits-2))
+			} else {
+				line.WriteString(strings.Repeat(fill, width))
+			}
+		}
+		res.WriteString(strings.TrimRight(line.String(), " "))
+		res.WriteByte('\n')
+		if x == rows {
+			break
+		}
+
+		line.Reset()
+		for i := len(o.clues[SideLeft]) - 1; i >= 0; i-- {
+			fmt.Fprintf(&line, "%*s ", layerWidths[SideLeft][i], o.clues[SideLeft][i][x])
+		}
+		for y := 0; y <= cols; y++ {
+			if sign, ok := o.left[x*cols+y]; ok && y < cols {
+				line.WriteRune(sign)
+			} else if differ(x, y-1, x, y) {
+				line.WriteByte('|')
+			} else if dots(x, y-1, x, y) {
+				line.WriteByte(':')
+			} else {
+				line.WriteByte(' ')
+			}
+			if y == cols {
+				break
+			}
+			if outside(x, y) {
+				line.WriteString(strings.Repeat(" ", width))
+				continue
+			}
+			switch {
+			case o.shaded == nil:
+				fmt.Fprintf(&line, "%-*s %c", digits, o.labels[x*cols+y], cells[x*cols+y])
+			case o.shaded[x*cols+y]:
+				fmt.Fprintf(&line, "%-*s[%c]", digits, o.labels[x*cols+y], cells[x*cols+y])
+			default:
+				fmt.Fprintf(&line, "%-*s %c ", digits, o.labels[x*cols+y], cells[x*cols+y])
+			}
+		}
+		for i, layer := range o.clues[SideRight] {
+			fmt.Fprintf(&line, " %*s", layerWidths[SideRight][i], layer[x])
+		}
+		res.WriteString(strings.TrimRight(line.String(), " "))
+		res.WriteByte('\n')
+	}
+	for _, layer := range o.clues[SideBottom] {
+		res.WriteString(columns(layer))
+	}
+	return res.String()
+}
+
+// validSize returns an error if the size of a grid is not positive, to be checked before anything is allocated for it
+func validSize(size int) error {
+	if size <= 0 {
+		return fmt.Errorf("%w: size must be positive, not %d", ErrInvalidDimensions, size)
+	}
+	return nil
+}
+
+// Valid returns all the errors if the SudokuGrid isn't valid, nil otherwise.
+// The partition dimensions of a jigsaw sudoku may be left to 0.
 func (sG *SudokuGrid) Valid() error {
-	if sG.Size%sG.PartitionHeight != 0 || sG.Size%sG.PartitionWidth != 0 || sG.Size%(sG.PartitionHeight*sG.PartitionWidth) != 0 {
-		return errors.New("size must be divisible by both partitionWidth and partitionHeight")
+	jigsaw := sG.Regions != nil && sG.PartitionWidth == 0 && sG.PartitionHeight == 0
+	if !jigsaw && (sG.PartitionWidth <= 0 || sG.PartitionHeight <= 0 || sG.PartitionWidth*sG.PartitionHeight != sG.Size) {
+		return fmt.Errorf("%w: size must be equal to partitionWidth * partitionHeight", ErrInvalidDimensions)
+	}
+	if err := validSize(sG.Size); err != nil {
+		return err
 	}
 	if len(sG.Grid) != sG.Size {
-		return errors.New("the given grid size does not match the given size property")
+		return fmt.Errorf("%w: the given grid size does not match the given size property", ErrInvalidDimensions)
 	}
 
 	cnt := 0
@@ -330,7 +1120,53 @@ func (sG *SudokuGrid) Valid() error {
 		}
 	}
 	if cnt > 0 {
-		return fmt.Errorf("%d row(s) sizes do not match the given size property", cnt)
+		return fmt.Errorf("%w: %d row(s) sizes do not match the given size property", ErrInvalidDimensions, cnt)
+	}
+
+	if _, err := parseSymbols(sG.Symbols, sG.Size); err != nil {
+		return err
+	}
+
+	if err := validVariant(sG.Variant); err != nil {
+		return err
+	}
+
+	if err := sG.validRegions(); err != nil {
+		return err
+	}
+
+	if err := sG.validExtraRegions(); err != nil {
+		return err
+	}
+
+	if err := sG.validCages(); err != nil {
+		return err
+	}
+
+	if err := sG.validConstraints(); err != nil {
+		return err
+	}
+
+	if err := sG.validInequalities(); err != nil {
+		return err
+	}
+
+	if err := sG.validClues(); err != nil {
+		return err
+	}
+
+	if sG.PencilMarks != nil {
+		if len(sG.PencilMarks) != sG.Size {
+			return fmt.Errorf("%w: the given pencil marks size does not match the given size property", ErrInvalidDimensions)
+		}
+		for i := range sG.PencilMarks {
+			if len(sG.PencilMarks[i]) != sG.Size {
+				cnt++
+			}
+		}
+		if cnt > 0 {
+			return fmt.Errorf("%w: %d pencil marks row(s) sizes do not match the given size property", ErrInvalidDimensions, cnt)
+		}
 	}
 
 	return nil
@@ -1,7 +1,12 @@
 package sudoku
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -30,6 +35,15 @@ var _ = Describe("Sudoku", func() {
 			Expect(err).NotTo(BeNil())
 			Expect(sG).To(BeNil())
 		})
+		It("rejects sizes that are not positive", func() {
+			for _, d := range [][3]int{{0, 0, 0}, {0, 1, 0}, {-1, -1, 1}, {-4, 2, -2}, {-4, -2, -2}} {
+				sG, err := New(d[0], d[1], d[2])
+				Expect(err).To(MatchError(ErrInvalidDimensions), "%v", d)
+				Expect(sG).To(BeNil())
+				_, err = GenerateSudokuGrid(d[0], d[1], d[2])
+				Expect(err).To(MatchError(ErrInvalidDimensions), "%v", d)
+			}
+		})
 	})
 
 	Context("Sudoku Grid serialization", func() {
@@ -54,6 +68,354 @@ var _ = Describe("Sudoku", func() {
 		})
 	})
 
+	Context("Counting solutions", func() {
+		It("reports a unique solution for a well-formed puzzle", func() {
+			sG := &SudokuGrid{}
+			err := json.Unmarshal([]byte(`{"size":4,"partitionWidth":2,"partitionHeight":2,"grid":[[49,46,46,52],[46,52,49,46],[50,46,46,51],[52,46,50,46]]}`), sG)
+			Expect(err).To(BeNil())
+
+			Expect(sG.CountSolutions(0)).To(Equal(1))
+			Expect(sG.IsUnique()).To(BeTrue())
+
+			solutions := sG.FindSolutions(2)
+			Expect(solutions).To(HaveLen(1))
+			Expect(string(solutions[0].Grid[0])).To(Equal("1234"))
+			Expect(sG.Grid[0][1]).To(Equal(EMPTY_CELL))
+		})
+
+		It("stops counting once the limit is reached", func() {
+			sG, err := New(4, 2, 2)
+			Expect(err).To(BeNil())
+
+			Expect(sG.CountSolutions(0)).To(Equal(288))
+			Expect(sG.CountSolutions(5)).To(Equal(5))
+			Expect(sG.IsUnique()).To(BeFalse())
+
+			solutions := sG.FindSolutions(2)
+			Expect(solutions).To(HaveLen(2))
+			Expect(solutions[0].Grid).NotTo(Equal(solutions[1].Grid))
+		})
+
+		It("returns no solution for an unsolvable puzzle", func() {
+			sG, err := New(4, 2, 2)
+			Expect(err).To(BeNil())
+			sG.Set(0, 0, '1')
+			sG.Set(0, 1, '2')
+			sG.Set(0, 2, '3')
+			sG.Set(2, 3, '4')
+
+			Expect(sG.CountSolutions(2)).To(Equal(0))
+			Expect(sG.FindSolutions(2)).To(BeEmpty())
+			Expect(sG.IsUnique()).To(BeFalse())
+		})
+	})
+
+	Context("Grid dimensions", func() {
+		DescribeTable("supports subgrids of partitionWidth x partitionHeight cells",
+			func(size, partitionWidth, partitionHeight int) {
+				sG, err := New(size, partitionWidth, partitionHeight)
+				Expect(err).To(BeNil())
+
+				// each subgrid holds size cells spanning partitionHeight rows and partitionWidth columns
+				cells := map[int][]coord{}
+				for x := 0; x < size; x++ {
+					for y := 0; y < size; y++ {
+						box := sG.GetSubgridIndex(x, y)
+						Expect(box).To(BeNumerically("<", size))
+						cells[box] = append(cells[box], coord{x, y})
+					}
+				}
+				Expect(cells).To(HaveLen(size))
+				for _, box := range cells {
+					Expect(box).To(HaveLen(size))
+					first, last := box[0], box[len(box)-1]
+					Expect(last.x - first.x).To(Equal(partitionHeight - 1))
+					Expect(last.y - first.y).To(Equal(partitionWidth - 1))
+				}
+
+				sG, err = GenerateSudokuGrid(size, partitionWidth, partitionHeight)
+				Expect(err).To(BeNil())
+				Expect(isSolved(sG)).To(BeTrue())
+				solution := sG.Clone()
+				Expect(sG.SetGridToLevel("easy", WithUniqueSolution())).To(Succeed())
+				for _, name := range Solvers() {
+					s, err := GetSolver(name)
+					Expect(err).To(BeNil())
+					puzzle := sG.Clone()
+					_, err = puzzle.SolveWith(context.Background(), s)
+					Expect(err).To(BeNil())
+					Expect(puzzle.Grid).To(Equal(solution.Grid))
+				}
+
+				// every line of the pretty output has the same width
+				lines := strings.Split(strings.TrimSuffix(solution.ToStringPrettify(), "\n"), "\n")
+				Expect(lines).To(HaveLen(size + partitionWidth - 1))
+				for _, line := range lines {
+					Expect(len(line)).To(Equal(size*3 + partitionHeight - 1))
+				}
+			},
+			Entry("4x4", 4, 2, 2),
+			Entry("6x6 with 3x2 subgrids", 6, 3, 2),
+			Entry("6x6 with 2x3 subgrids", 6, 2, 3),
+			Entry("8x8 with 4x2 subgrids", 8, 4, 2),
+			Entry("10x10 with 5x2 subgrids", 10, 5, 2),
+			Entry("12x12 with 4x3 subgrids", 12, 4, 3),
+			Entry("12x12 with 3x4 subgrids", 12, 3, 4),
+			Entry("16x16", 16, 4, 4),
+		)
+
+		It("rejects subgrids not covering the grid exactly", func() {
+			for _, d := range [][3]int{{8, 2, 2}, {12, 6, 6}, {6, 4, 2}, {6, 0, 6}, {9, 3, 0}} {
+				sG, err := New(d[0], d[1], d[2])
+				Expect(err).NotTo(BeNil(), "%v", d)
+				Expect(sG).To(BeNil())
+			}
+		})
+	})
+
+	Context("Generating grids", func() {
+		It("generates solved grids of various dimensions", func() {
+			for _, d := range [][3]int{{4, 2, 2}, {6, 3, 2}, {8, 2, 4}, {9, 3, 3}, {12, 4, 3}, {16, 4, 4}, {25, 5, 5}} {
+				for i := 0; i < 5; i++ {
+					sG, err := GenerateSudokuGrid(d[0], d[1], d[2])
+					Expect(err).To(BeNil())
+					Expect(isSolved(sG)).To(BeTrue())
+				}
+			}
+		})
+
+		It("generates grids that are not relabelings of each other", func() {
+			// the cells holding the same value as the top-left one form a pattern kept by relabeling
+			pattern := func(sG *SudokuGrid) [][]bool {
+				res := make([][]bool, sG.Size)
+				for x := range sG.Grid {
+					res[x] = make([]bool, sG.Size)
+					for y, v := range sG.Grid[x] {
+						res[x][y] = v == sG.Grid[0][0]
+					}
+				}
+				return res
+			}
+			patterns := map[string]bool{}
+			for i := 0; i < 10; i++ {
+				sG, err := GenerateSudokuGrid(9, 3, 3)
+				Expect(err).To(BeNil())
+				patterns[fmt.Sprint(pattern(sG))] = true
+			}
+			Expect(len(patterns)).To(BeNumerically(">", 1))
+		})
+	})
+
+	Context("Generating puzzles from a seed", func() {
+		DescribeTable("generates the same puzzle from the same seed",
+			func(generate func(seed int64) (*SudokuGrid, error)) {
+				first, err := generate(42)
+				Expect(err).To(BeNil())
+				second, err := generate(42)
+				Expect(err).To(BeNil())
+				b1, err := json.Marshal(first)
+				Expect(err).To(BeNil())
+				b2, err := json.Marshal(second)
+				Expect(err).To(BeNil())
+				Expect(string(b2)).To(Equal(string(b1)))
+				Expect(*first.Seed).To(Equal(int64(42)))
+
+				other, err := generate(43)
+				Expect(err).To(BeNil())
+				other.Seed = first.Seed
+				b3, err := json.Marshal(other)
+				Expect(err).To(BeNil())
+				Expect(string(b3)).NotTo(Equal(string(b1)))
+			},
+			Entry("classic", func(seed int64) (*SudokuGrid, error) {
+				sG, err := GenerateSudokuGrid(9, 3, 3, WithSeed(seed))
+				if err != nil {
+					return nil, err
+				}
+				return sG, sG.SetGridToLevel("hard", WithUniqueSolution())
+			}),
+			Entry("jigsaw", func(seed int64) (*SudokuGrid, error) {
+				sG, err := GenerateSudokuGrid(6, 0, 0, WithSeed(seed), WithJigsaw())
+				if err != nil {
+					return nil, err
+				}
+				return sG, sG.SetGridToLevel("medium", WithUniqueSolution())
+			}),
+			Entry("killer", func(seed int64) (*SudokuGrid, error) {
+				sG, err := GenerateSudokuGrid(6, 3, 2, WithSeed(seed))
+				if err != nil {
+					return nil, err
+				}
+				return sG, sG.SetGridToKiller()
+			}),
+			Entry("inequalities", func(seed int64) (*SudokuGrid, error) {
+				sG, err := GenerateSudokuGrid(6, 3, 2, WithSeed(seed))
+				if err != nil {
+					return nil, err
+				}
+				return sG, sG.SetGridToInequalities()
+			}),
+			Entry("clues", func(seed int64) (*SudokuGrid, error) {
+				sG, err := GenerateSudokuGrid(6, 3, 2, WithSeed(seed))
+				if err != nil {
+					return nil, err
+				}
+				return sG, sG.SetGridToClues(ClueSkyscraper)
+			}),
+		)
+
+		It("removes the same cells from the same source", func() {
+			sG, err := GenerateSudokuGrid(9, 3, 3)
+			Expect(err).To(BeNil())
+			Expect(sG.Seed).To(BeNil())
+			other := sG.Clone()
+			Expect(sG.SetGridToLevel("medium", WithRand(rand.New(rand.NewSource(7))))).To(Succeed())
+			Expect(other.SetGridToLevel("medium", WithRand(rand.New(rand.NewSource(7))))).To(Succeed())
+			Expect(other.Grid).To(Equal(sG.Grid))
+		})
+
+		It("serializes and copies the seed", func() {
+			sG, err := GenerateSudokuGrid(4, 2, 2, WithSeed(-1))
+			Expect(err).To(BeNil())
+			b, err := json.Marshal(sG)
+			Expect(err).To(BeNil())
+			Expect(string(b)).To(ContainSubstring(`"seed":-1`))
+			parsed := &SudokuGrid{}
+			Expect(json.Unmarshal(b, parsed)).To(Succeed())
+			Expect(*parsed.Seed).To(Equal(int64(-1)))
+
+			clone := sG.Clone()
+			*clone.Seed = 0
+			Expect(*sG.Seed).To(Equal(int64(-1)))
+		})
+	})
+
+	Context("Generating puzzles with a unique solution", func() {
+		countEmpty := func(sG *SudokuGrid) int {
+			cnt := 0
+			for i := range sG.Grid {
+				for j := range sG.Grid[i] {
+					if sG.Grid[i][j] == EMPTY_CELL {
+						cnt++
+					}
+				}
+			}
+			return cnt
+		}
+
+		It("keeps the solution unique while removing clues", func() {
+			sG, err := GenerateSudokuGrid(9, 3, 3)
+			Expect(err).To(BeNil())
+			solution := sG.Clone()
+
+			Expect(sG.SetGridToLevel("hard", WithUniqueSolution())).To(Succeed())
+			Expect(countEmpty(sG)).To(BeNumerically(">", 0))
+			Expect(countEmpty(sG)).To(BeNumerically("<=", 64))
+			Expect(sG.IsUnique()).To(BeTrue())
+
+			solutions := sG.FindSolutions(0)
+			Expect(solutions).To(HaveLen(1))
+			Expect(solutions[0].Grid).To(Equal(solution.Grid))
+		})
+
+		It("produces a minimal puzzle when every clue may be removed", func() {
+			sG, err := GenerateSudokuGrid(4, 2, 2)
+			Expect(err).To(BeNil())
+
+			Expect(sG.SetGridToLevel("robot", WithUniqueSolution())).To(Succeed())
+			Expect(sG.IsUnique()).To(BeTrue())
+			for i := range sG.Grid {
+				for j := range sG.Grid[i] {
+					if sG.Grid[i][j] == EMPTY_CELL {
+						continue
+					}
+					clue := sG.Grid[i][j]
+					sG.Set(i, j, EMPTY_CELL)
+					Expect(sG.IsUnique()).To(BeFalse())
+					sG.Set(i, j, clue)
+				}
+			}
+		})
+
+		It("stops removing clues once the nodes of the attempt are used", func() {
+			sG, err := GenerateSudokuGrid(9, 3, 3, WithSeed(1))
+			Expect(err).To(BeNil())
+
+			// every removal is graded, which counts as gradeNodes nodes per cell
+			maxNodes := 5 * gradeNodes * 81
+			grade, nodes, err := sG.removeCluesWithin(context.Background(), 81, maxLogicalScore+1, maxLogicalScore+2, maxNodes)
+			Expect(err).To(BeNil())
+			Expect(grade).NotTo(BeNil())
+			Expect(nodes).To(BeNumerically(">=", maxNodes))
+			Expect(nodes).To(BeNumerically("<", maxNodes+gradeNodes*81))
+			Expect(len(sG.missingCells())).To(BeNumerically("<=", 4))
+			Expect(sG.IsUnique()).To(BeTrue())
+		})
+
+		It("returns an error for an unknown level", func() {
+			sG, err := GenerateSudokuGrid(4, 2, 2)
+			Expect(err).To(BeNil())
+			Expect(sG.SetGridToLevel("unknown", WithUniqueSolution())).NotTo(Succeed())
+		})
+	})
+
+	Context("Generating puzzles to a graded difficulty", func() {
+		It("produces puzzles graded at the requested level", func() {
+			for _, level := range []string{"easy", "robot"} {
+				sG, err := GenerateSudokuGrid(9, 3, 3)
+				Expect(err).To(BeNil())
+				solution := sG.Clone()
+
+				// a generous number of attempts, about a fourth of the minimal puzzles need guessing
+				Expect(sG.SetGridToLevel(level, WithGradedDifficulty(50))).To(Succeed())
+				d, err := Grade(sG)
+				Expect(err).To(BeNil())
+				Expect(d.Level).To(Equal(level))
+
+				solutions := sG.FindSolutions(0)
+				Expect(solutions).To(HaveLen(1))
+				Expect(solutions[0].Grid).To(Equal(solution.Grid))
+			}
+		})
+
+		It("keeps the closest puzzle and describes it when the level cannot be reached", func() {
+			// every 4x4 puzzle with a unique solution is solved with singles
+			sG, err := GenerateSudokuGrid(4, 2, 2)
+			Expect(err).To(BeNil())
+
+			err = sG.SetGridToLevel("extreme", WithGradedDifficulty(3))
+			Expect(err).To(MatchError(ContainSubstring("in 3 attempts, the closest one is graded easy")))
+			Expect(err).To(MatchError(ErrLevelNotReached))
+			var lerr *LevelError
+			Expect(errors.As(err, &lerr)).To(BeTrue())
+			Expect(lerr.Closest.Level).To(Equal("easy"))
+			Expect(sG.IsUnique()).To(BeTrue())
+			Expect(sG.missingCells()).NotTo(BeEmpty())
+		})
+
+		It("stops removing clues once the nodes of the attempt are used", func() {
+			sG, err := GenerateSudokuGrid(9, 3, 3, WithSeed(1))
+			Expect(err).To(BeNil())
+
+			// every removal is graded, which counts as gradeNodes nodes per cell
+			maxNodes := 5 * gradeNodes * 81
+			grade, nodes, err := sG.removeCluesWithin(context.Background(), 81, maxLogicalScore+1, maxLogicalScore+2, maxNodes)
+			Expect(err).To(BeNil())
+			Expect(grade).NotTo(BeNil())
+			Expect(nodes).To(BeNumerically(">=", maxNodes))
+			Expect(nodes).To(BeNumerically("<", maxNodes+gradeNodes*81))
+			Expect(len(sG.missingCells())).To(BeNumerically("<=", 4))
+			Expect(sG.IsUnique()).To(BeTrue())
+		})
+
+		It("returns an error for an unknown level", func() {
+			sG, err := GenerateSudokuGrid(4, 2, 2)
+			Expect(err).To(BeNil())
+			Expect(sG.SetGridToLevel("unknown", WithGradedDifficulty(1))).NotTo(Succeed())
+			Expect(isSolved(sG)).To(BeTrue())
+		})
+	})
+
 	Context("Helper functions", func() {
 		var (
 			sG *SudokuGrid
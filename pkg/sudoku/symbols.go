@@ -0,0 +1,96 @@
+package sudoku
+
+import "fmt"
+
+const (
+	// DefaultSymbols is the alphabet of the grids without symbols: digits first, then letters
+	DefaultSymbols = "123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// HexSymbols is the alphabet of hexadecimal digits, for grids up to 16x16
+	HexSymbols = "0123456789ABCDEF"
+)
+
+// SymbolSet returns the symbols of a grid of the given size for the named alphabet, either "default" or "hex".
+// Any other name is taken as a user-defined alphabet listing exactly size symbols.
+func SymbolSet(name string, size int) (string, error) {
+	if err := validSize(size); err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = "default"
+	}
+	var alphabet string
+	switch name {
+	case "default":
+		alphabet = DefaultSymbols
+	case "hex":
+		alphabet = HexSymbols
+	default:
+		if _, err := parseSymbols(name, size); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	if size > len(alphabet) {
+		return "", fmt.Errorf("%w: the %s symbols only support grids up to %dx%d", ErrInvalidSymbol, name, len(alphabet), len(alphabet))
+	}
+	return alphabet[:size], nil
+}
+
+// parseSymbols returns the runes of symbols, DefaultSymbols if empty, and checks they make a valid alphabet for
+// a grid of the given size: exactly size distinct symbols, none of them being EMPTY_CELL
+func parseSymbols(symbols string, size int) ([]rune, error) {
+	if err := validSize(size); err != nil {
+		return nil, err
+	}
+	if symbols == "" {
+		if size > len(DefaultSymbols) {
+			return nil, fmt.Errorf("%w: grids larger than %dx%d need user-defined symbols", ErrInvalidSymbol, len(DefaultSymbols), len(DefaultSymbols))
+		}
+		return []rune(DefaultSymbols[:size]), nil
+	}
+
+	runes := []rune(symbols)
+	if len(runes) != size {
+		return nil, fmt.Errorf("%w: %d symbols given for a grid of size %d", ErrInvalidSymbol, len(runes), size)
+	}
+	seen := make(map[rune]bool, len(runes))
+	for _, r := range runes {
+		if r == EMPTY_CELL {
+			return nil, fmt.Errorf("%w: the symbol %q is reserved for empty cells", ErrInvalidSymbol, EMPTY_CELL)
+		}
+		if seen[r] {
+			return nil, fmt.Errorf("%w: the symbol %q is given twice", ErrInvalidSymbol, r)
+		}
+		seen[r] = true
+	}
+	return runes, nil
+}
+
+// customSymbols returns the symbols, or "" if they are the default ones of a grid of the given size, which the
+// grids leave out of their Symbols
+func customSymbols(symbols string, size int) string {
+	if size <= len(DefaultSymbols) && symbols == DefaultSymbols[:size] {
+		return ""
+	}
+	return symbols
+}
+
+// WithSymbols generates grids using the given symbols, see SymbolSet
+func WithSymbols(symbols string) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.symbols = symbols
+	}
+}
+
+// symbols returns the alphabet of the SudokuGrid in order, unlike allowedValues which the generator shuffles.
+// The SudokuGrid is expected to be valid.
+func (sG *SudokuGrid) symbols() []rune {
+	if sG.Symbols != "" {
+		return []rune(sG.Symbols)
+	}
+	n := sG.Size
+	if n > len(DefaultSymbols) {
+		n = len(DefaultSymbols)
+	}
+	return []rune(DefaultSymbols[:n])
+}
@@ -0,0 +1,117 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// usesOnly returns true if every cell of the SudokuGrid holds one of the symbols
+func usesOnly(sG *SudokuGrid, symbols string) bool {
+	for _, row := range sG.Grid {
+		for _, v := range row {
+			if !strings.ContainsRune(symbols, v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+var _ = Describe("Symbols", func() {
+	It("uses digits then letters by default", func() {
+		sG, err := GenerateSudokuGrid(16, 4, 4)
+		Expect(err).To(BeNil())
+		Expect(isSolved(sG)).To(BeTrue())
+		Expect(usesOnly(sG, "123456789ABCDEFG")).To(BeTrue())
+		Expect(sG.Symbols).To(BeEmpty())
+
+		// the default symbols are left out even when given
+		symbols, err := SymbolSet("default", 9)
+		Expect(err).To(BeNil())
+		for _, opts := range [][]GeneratorOption{{WithSymbols(symbols)}, {WithSymbols(symbols), WithJigsaw()}} {
+			sG, err = GenerateSudokuGrid(9, 3, 3, opts...)
+			Expect(err).To(BeNil())
+			Expect(sG.Symbols).To(BeEmpty())
+			b, err := json.Marshal(sG)
+			Expect(err).To(BeNil())
+			Expect(string(b)).NotTo(ContainSubstring(`"symbols"`))
+		}
+		m, err := GenerateSamurai(9, 3, 3, WithSymbols(symbols))
+		Expect(err).To(BeNil())
+		for _, g := range m.Grids {
+			Expect(g.Symbols).To(BeEmpty())
+		}
+	})
+
+	It("generates and solves grids with the given symbols", func() {
+		symbols, err := SymbolSet("hex", 16)
+		Expect(err).To(BeNil())
+		Expect(symbols).To(Equal(HexSymbols))
+
+		sG, err := GenerateSudokuGrid(16, 4, 4, WithSymbols(symbols))
+		Expect(err).To(BeNil())
+		Expect(isSolved(sG)).To(BeTrue())
+		Expect(usesOnly(sG, HexSymbols)).To(BeTrue())
+		Expect(sG.ToStringPrettify()).To(ContainSubstring("0"))
+
+		solution := sG.Clone()
+		Expect(sG.SetGridToLevel("easy", WithUniqueSolution())).To(Succeed())
+		Expect(sG.Solve()).To(Succeed())
+		Expect(sG.Grid).To(Equal(solution.Grid))
+	})
+
+	It("solves grids with user-defined symbols", func() {
+		sG, err := NewWithSymbols(4, 2, 2, "WXYZ")
+		Expect(err).To(BeNil())
+		for i, row := range []string{"W..Z", ".ZW.", "X..Y", "Z.X."} {
+			for j, v := range row {
+				sG.Set(i, j, v)
+			}
+		}
+		Expect(sG.IsUnique()).To(BeTrue())
+		Expect(sG.Solve()).To(Succeed())
+		Expect(string(sG.Grid[0])).To(Equal("WXYZ"))
+		Expect(sG.Candidates()[0]).To(Equal([][]rune{nil, nil, nil, nil}))
+	})
+
+	It("serializes the symbols", func() {
+		sG, err := NewWithSymbols(4, 2, 2, "WXYZ")
+		Expect(err).To(BeNil())
+		b, err := json.Marshal(sG)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(ContainSubstring(`"symbols":"WXYZ"`))
+
+		other := &SudokuGrid{}
+		Expect(json.Unmarshal(b, other)).To(Succeed())
+		Expect(other.Symbols).To(Equal("WXYZ"))
+		Expect(other.allowedValues).To(Equal([]rune("WXYZ")))
+	})
+
+	It("rejects invalid symbols", func() {
+		_, err := SymbolSet("hex", 25)
+		Expect(err).NotTo(BeNil())
+		_, err = SymbolSet("ABC", 4)
+		Expect(err).NotTo(BeNil())
+		_, err = SymbolSet("ABCA", 4)
+		Expect(err).NotTo(BeNil())
+		_, err = SymbolSet("AB.C", 4)
+		Expect(err).NotTo(BeNil())
+		for _, size := range []int{0, -1} {
+			_, err = SymbolSet("", size)
+			Expect(err).To(MatchError(ErrInvalidDimensions))
+			_, err = SymbolSet("hex", size)
+			Expect(err).To(MatchError(ErrInvalidDimensions))
+			_, err = parseSymbols("", size)
+			Expect(err).To(MatchError(ErrInvalidDimensions))
+		}
+
+		_, err = NewWithSymbols(4, 2, 2, "123")
+		Expect(err).NotTo(BeNil())
+		_, err = New(36, 6, 6)
+		Expect(err).NotTo(BeNil())
+		Expect(json.Unmarshal([]byte(`{"size":4,"partitionWidth":2,"partitionHeight":2,"symbols":"AABB","grid":[[46,46,46,46],[46,46,46,46],[46,46,46,46],[46,46,46,46]]}`), &SudokuGrid{})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,155 @@
+package sudoku
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Conflict is a value held by two cells of the same unit, it is reported for each of them
+type Conflict struct {
+	Candidate
+	Unit  string `json:"unit"`  // kind of the unit holding the value twice: row, column, box, diagonal or extra region
+	Index int    `json:"index"` // index of the unit among the units of the same kind, starting from 0
+}
+
+// Violation is a value breaking one of the constraints of the grid, it is reported for each cell involved
+type Violation struct {
+	Candidate
+	Constraint int    `json:"constraint"` // index of the constraint, inequality or clue in those of the grid, starting from 0
+	Type       string `json:"type"`       // type of the constraint, see Constraint.Type
+}
+
+// ValidationError lists the cells of a SudokuGrid breaking the rules of the puzzle, in row-major order
+type ValidationError struct {
+	InvalidSymbols []Candidate `json:"invalidSymbols,omitempty"` // values which are not symbols of the grid
+	Conflicts      []Conflict  `json:"conflicts,omitempty"`
+	CageSums       []CageSum   `json:"cageSums,omitempty"` // cages of a Killer Sudoku whose values break their sum
+	Violations     []Violation `json:"violations,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "the grid breaks the rules of the puzzle:")
+	for _, c := range e.InvalidSymbols {
+		fmt.Fprintf(&b, " %q in r%dc%d is not a symbol of the grid;", c.Value, c.Row+1, c.Col+1)
+	}
+	for _, c := range e.Conflicts {
+		fmt.Fprintf(&b, " %c in r%dc%d is repeated in %s %d;", c.Value, c.Row+1, c.Col+1, c.Unit, c.Index+1)
+	}
+	for _, c := range e.CageSums {
+		fmt.Fprintf(&b, " the values of cage %d add up to %d;", c.Cage+1, c.Sum)
+	}
+	for _, c := range e.Violations {
+		fmt.Fprintf(&b, " %c in r%dc%d breaks the %s constraint %d;", c.Value, c.Row+1, c.Col+1, c.Type, c.Constraint+1)
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// Validate is like Valid but also checks the values of the cells: each of them is either EMPTY_CELL or one of
+// the symbols of the grid, no unit nor cage holds the same value twice, the values of a cage do not exceed
+// its sum nor add up to another one once it is full, and the constraints of the grid allow every value.
+// A *ValidationError is returned otherwise.
+func (sG *SudokuGrid) Validate() error {
+	if err := sG.Valid(); err != nil {
+		return err
+	}
+
+	res := &ValidationError{}
+	symbols := make(map[rune]bool, sG.Size)
+	for _, v := range sG.symbols() {
+		symbols[v] = true
+	}
+	for i := range sG.Grid {
+		for j, v := range sG.Grid[i] {
+			if v != EMPTY_CELL && !symbols[v] {
+				res.InvalidSymbols = append(res.InvalidSymbols, Candidate{Cell: Cell{Row: i, Col: j}, Value: v})
+			}
+		}
+	}
+	res.Conflicts = sG.duplicates()
+	res.CageSums = sG.cageSums()
+	res.Violations = sG.violations()
+
+	if len(res.InvalidSymbols) == 0 && len(res.Conflicts) == 0 && len(res.CageSums) == 0 && len(res.Violations) == 0 {
+		return nil
+	}
+	return res
+}
+
+// duplicates returns the conflicts of the cells holding the same value as another cell of one of their units
+// or of their cage, in row-major order then by kind of unit, cages last
+func (sG *SudokuGrid) duplicates() []Conflict {
+	l := newLayout(sG)
+	var res []Conflict
+	add := func(group []int, unit string, index int) {
+		cells := map[rune][]int{}
+		for _, cell := range group {
+			if v := sG.Grid[cell/l.size][cell%l.size]; v != EMPTY_CELL {
+				cells[v] = append(cells[v], cell)
+			}
+		}
+		for v, same := range cells {
+			if len(same) < 2 {
+				continue
+			}
+			for _, cell := range same {
+				res = append(res, Conflict{
+					Candidate: Candidate{Cell: Cell{Row: cell / l.size, Col: cell % l.size}, Value: v},
+					Unit:      unit,
+					Index:     index,
+				})
+			}
+		}
+	}
+	for u, unit := range l.units {
+		add(unit, l.kinds[u].String(), l.indexes[u])
+	}
+	for c, cage := range l.cages {
+		add(cage, "cage", c)
+	}
+
+	// the units are sorted by kind, sorting the cells keeps them so for the same cell
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Row != res[j].Row {
+			return res[i].Row < res[j].Row
+		}
+		return res[i].Col < res[j].Col
+	})
+	return res
+}
+
+// violations returns the values of the filled cells the constraints, the inequalities or the clues do not allow
+// given the values of the other cells, in row-major order then by constraint, the inequalities and the clues last
+func (sG *SudokuGrid) violations() []Violation {
+	rules := sG.rules()
+	if len(rules) == 0 {
+		return nil
+	}
+	s, _ := sG.state()
+	var res []Violation
+	for cell, v := range s.Values {
+		if v == -1 {
+			continue
+		}
+		c := Cell{Row: cell / sG.Size, Col: cell % sG.Size}
+		for k, constraint := range rules {
+			if !constraint.Allows(s, c, v) {
+				// the index is counted among the constraints, the inequalities or the clues
+				index := k
+				for _, n := range []int{len(sG.Constraints), len(sG.Inequalities)} {
+					if index < n {
+						break
+					}
+					index -= n
+				}
+				res = append(res, Violation{
+					Candidate:  Candidate{Cell: c, Value: sG.Grid[c.Row][c.Col]},
+					Constraint: index,
+					Type:       constraint.Type(),
+				})
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,60 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	It("accepts a puzzle following the rules", func() {
+		Expect(parseGrid(9, 3, easyPuzzle).Validate()).To(Succeed())
+		Expect(patternGrid(9, 3, 3).Validate()).To(Succeed())
+	})
+
+	It("reports the cells holding the same value in a unit", func() {
+		sG := parseGrid(9, 3, easyPuzzle)
+		// r1c3 and r2c4 hold a 3, the player puts another one in their box and row
+		sG.Set(1, 1, '3')
+
+		err := sG.Validate()
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.InvalidSymbols).To(BeEmpty())
+		Expect(verr.Conflicts).To(Equal([]Conflict{
+			{Candidate: Candidate{Cell: Cell{Row: 0, Col: 2}, Value: '3'}, Unit: "box", Index: 0},
+			{Candidate: Candidate{Cell: Cell{Row: 1, Col: 1}, Value: '3'}, Unit: "row", Index: 1},
+			{Candidate: Candidate{Cell: Cell{Row: 1, Col: 1}, Value: '3'}, Unit: "box", Index: 0},
+			{Candidate: Candidate{Cell: Cell{Row: 1, Col: 3}, Value: '3'}, Unit: "row", Index: 1},
+		}))
+		Expect(err.Error()).To(ContainSubstring("3 in r2c2 is repeated in row 2"))
+		Expect(sG.conflicts()).To(Equal([]Cell{{0, 2}, {1, 1}, {1, 3}}))
+	})
+
+	It("reports the values which are not symbols of the grid", func() {
+		sG, err := NewWithSymbols(4, 2, 2, "WXYZ")
+		Expect(err).To(BeNil())
+		sG.Set(0, 0, 'W')
+		sG.Set(2, 3, '1')
+
+		var verr *ValidationError
+		Expect(errors.As(sG.Validate(), &verr)).To(BeTrue())
+		Expect(verr.Conflicts).To(BeEmpty())
+		Expect(verr.InvalidSymbols).To(Equal([]Candidate{{Cell: Cell{Row: 2, Col: 3}, Value: '1'}}))
+
+		b, err := json.Marshal(verr)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(Equal(`{"invalidSymbols":[{"row":2,"col":3,"value":49}]}`))
+	})
+
+	It("checks the dimensions first", func() {
+		sG := patternGrid(9, 3, 3)
+		sG.Grid = sG.Grid[1:]
+		err := sG.Validate()
+		Expect(err).NotTo(BeNil())
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeFalse())
+	})
+})
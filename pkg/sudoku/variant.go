@@ -0,0 +1,35 @@
+package sudoku
+
+import "fmt"
+
+const (
+	// ClassicVariant is the standard sudoku: every row, column and subgrid holds each symbol once
+	ClassicVariant = "classic"
+	// DiagonalVariant is the classic sudoku where both main diagonals also hold each symbol once, a.k.a. Sudoku X
+	DiagonalVariant = "diagonal"
+)
+
+// Variants returns the names of the supported variants
+func Variants() []string {
+	return []string{ClassicVariant, DiagonalVariant}
+}
+
+// validVariant returns an error if variant is not one of Variants, the empty variant is the ClassicVariant
+func validVariant(variant string) error {
+	if variant == "" {
+		return nil
+	}
+	for _, v := range Variants() {
+		if v == variant {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q: must be one of the supported variants (classic, diagonal)", ErrInvalidVariant, variant)
+}
+
+// WithVariant generates grids following the rules of the given variant, see Variants
+func WithVariant(variant string) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.variant = variant
+	}
+}
@@ -0,0 +1,119 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// diagonalsDistinct returns true if no value is repeated on the main diagonal nor on the anti-diagonal
+func diagonalsDistinct(sG *SudokuGrid) bool {
+	main, anti := map[rune]bool{}, map[rune]bool{}
+	for x := 0; x < sG.Size; x++ {
+		v, w := sG.Grid[x][x], sG.Grid[x][sG.Size-1-x]
+		if (v != EMPTY_CELL && main[v]) || (w != EMPTY_CELL && anti[w]) {
+			return false
+		}
+		main[v], anti[w] = true, true
+	}
+	return true
+}
+
+var _ = Describe("Variants", func() {
+	Context("Diagonal", func() {
+		DescribeTable("generates grids with distinct values on both diagonals",
+			func(size, partitionWidth, partitionHeight int) {
+				sG, err := GenerateSudokuGrid(size, partitionWidth, partitionHeight, WithVariant(DiagonalVariant))
+				Expect(err).To(BeNil())
+				Expect(sG.Variant).To(Equal(DiagonalVariant))
+				Expect(isSolved(sG)).To(BeTrue())
+				Expect(diagonalsDistinct(sG)).To(BeTrue())
+			},
+			Entry("4x4", 4, 2, 2),
+			Entry("6x6", 6, 3, 2),
+			Entry("9x9", 9, 3, 3),
+			Entry("12x12", 12, 4, 3),
+			Entry("16x16", 16, 4, 4),
+		)
+
+		It("generates puzzles whose unique solution follows the diagonals", func() {
+			sG, err := GenerateSudokuGrid(9, 3, 3, WithVariant(DiagonalVariant))
+			Expect(err).To(BeNil())
+			solution := sG.Clone()
+			Expect(sG.SetGridToLevel("medium", WithGradedDifficulty(DefaultGradingAttempts))).To(Succeed())
+			Expect(sG.IsUnique()).To(BeTrue())
+
+			for _, name := range Solvers() {
+				s, err := GetSolver(name)
+				Expect(err).To(BeNil())
+				puzzle := sG.Clone()
+				_, err = puzzle.SolveWith(context.Background(), s)
+				Expect(err).To(BeNil())
+				Expect(puzzle.Grid).To(Equal(solution.Grid))
+			}
+			puzzle := sG.Clone()
+			logical, err := puzzle.SolveLogically()
+			Expect(err).To(BeNil())
+			Expect(logical.Stuck).To(BeFalse())
+			Expect(puzzle.Grid).To(Equal(solution.Grid))
+		})
+
+		It("takes the diagonals into account when counting the solutions", func() {
+			// the empty 4x4 grid has 288 solutions, 48 of them with distinct values on the diagonals
+			sG, err := New(4, 2, 2)
+			Expect(err).To(BeNil())
+			Expect(sG.CountSolutions(0)).To(Equal(288))
+			sG.Variant = DiagonalVariant
+			Expect(sG.CountSolutions(0)).To(Equal(48))
+			for _, solution := range sG.FindSolutions(0) {
+				Expect(diagonalsDistinct(solution)).To(BeTrue())
+			}
+		})
+
+		It("reports the values repeated on a diagonal", func() {
+			sG := patternGrid(9, 3, 3)
+			Expect(sG.Validate()).To(Succeed())
+			sG.Variant = DiagonalVariant
+			err := sG.Validate()
+			Expect(err).To(MatchError(ErrConflict))
+			var verr *ValidationError
+			Expect(errors.As(err, &verr)).To(BeTrue())
+			for _, c := range verr.Conflicts {
+				Expect(c.Unit).To(Equal("diagonal"))
+			}
+		})
+
+		It("removes the values of the diagonals from the candidates", func() {
+			sG, err := New(4, 2, 2)
+			Expect(err).To(BeNil())
+			sG.Variant = DiagonalVariant
+			sG.Set(0, 0, '1')
+			sG.Set(0, 3, '2')
+			// r3c3 sees the 1 on the main diagonal only, r3c2 the 2 on the anti-diagonal only
+			Expect(sG.Candidates()[2][2]).To(Equal([]rune("234")))
+			Expect(sG.Candidates()[2][1]).To(Equal([]rune("134")))
+		})
+
+		It("serializes the variant", func() {
+			b, err := json.Marshal(&SudokuGrid{Size: 4, PartitionWidth: 2, PartitionHeight: 2, Variant: DiagonalVariant})
+			Expect(err).To(BeNil())
+			Expect(string(b)).To(ContainSubstring(`"variant":"diagonal"`))
+
+			sG := &SudokuGrid{}
+			Expect(json.Unmarshal([]byte(`{"size":4,"partitionWidth":2,"partitionHeight":2,"variant":"diagonal","grid":[[49,46,46,46],[46,49,46,46],[46,46,46,46],[46,46,46,46]]}`), sG)).To(Succeed())
+			Expect(sG.Clone().Variant).To(Equal(DiagonalVariant))
+			Expect(sG.Validate()).To(MatchError(ErrConflict))
+		})
+	})
+
+	It("rejects unknown variants", func() {
+		_, err := GenerateSudokuGrid(9, 3, 3, WithVariant("anti-knight"))
+		Expect(err).NotTo(BeNil())
+		sG := patternGrid(9, 3, 3)
+		sG.Variant = "anti-knight"
+		Expect(sG.Valid()).NotTo(Succeed())
+	})
+})